@@ -1,12 +1,14 @@
 package accesstoken
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
 	"github.com/helloferdie/stdgo/audittrail/event"
 	"github.com/helloferdie/stdgo/db"
 	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libstring"
 	"github.com/jmoiron/sqlx"
 )
@@ -29,8 +31,22 @@ type AccessToken struct {
 var moduleName = "access_token"
 var table = "access_tokens"
 
-// Create -
+// listFilters - Default filters of List
+var listFilters = []libquery.Config{
+	{Param: "id", Condition: "equal"},
+	{Param: "account_id", Condition: "equal"},
+	{Param: "client_id", Condition: "equal"},
+	{Param: "user_id", Condition: "equal"},
+	{Param: "is_revoke", Condition: "equal"},
+}
+
+// Create - Create without request ID in audit trail, use CreateContext with c.Request().Context() in echo handler
 func (at *AccessToken) Create(d *sqlx.DB, creatorID int64) (string, error) {
+	return at.CreateContext(context.Background(), d, creatorID)
+}
+
+// CreateContext - Create with request ID of ctx in audit trail
+func (at *AccessToken) CreateContext(ctx context.Context, d *sqlx.DB, creatorID int64) (string, error) {
 	at.CreatedAt.Valid = true
 	at.CreatedAt.Time = time.Now().UTC()
 	at.UpdatedAt.Valid = true
@@ -40,7 +56,7 @@ func (at *AccessToken) Create(d *sqlx.DB, creatorID int64) (string, error) {
 	_, _, err := db.Exec(d, query, val)
 	if err == nil {
 		at.GetByID(d, at.ID)
-		go event.CreateAuditTrail(map[string]interface{}{
+		go event.CreateAuditTrailContext(ctx, map[string]interface{}{
 			"operation":   "add",
 			"module_name": moduleName,
 			"table_name":  table,
@@ -53,8 +69,13 @@ func (at *AccessToken) Create(d *sqlx.DB, creatorID int64) (string, error) {
 	return at.ID, err
 }
 
-// Save -
+// Save - Save without request ID in audit trail, use SaveContext with c.Request().Context() in echo handler
 func (at *AccessToken) Save(d *sqlx.DB, creatorID int64) error {
+	return at.SaveContext(context.Background(), d, creatorID)
+}
+
+// SaveContext - Save with request ID of ctx in audit trail
+func (at *AccessToken) SaveContext(ctx context.Context, d *sqlx.DB, creatorID int64) error {
 	old := new(AccessToken)
 	old.GetByID(d, at.ID)
 
@@ -62,7 +83,7 @@ func (at *AccessToken) Save(d *sqlx.DB, creatorID int64) error {
 	if len(diff) > 0 {
 		_, _, err := db.Exec(d, query, val)
 		if err == nil {
-			go event.CreateAuditTrail(map[string]interface{}{
+			go event.CreateAuditTrailContext(ctx, map[string]interface{}{
 				"operation":   "edit",
 				"module_name": moduleName,
 				"table_name":  table,
@@ -77,8 +98,13 @@ func (at *AccessToken) Save(d *sqlx.DB, creatorID int64) error {
 	return nil
 }
 
-// Delete -
+// Delete - Delete without request ID in audit trail, use DeleteContext with c.Request().Context() in echo handler
 func (at *AccessToken) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
+	return at.DeleteContext(context.Background(), d, creatorID, softDelete)
+}
+
+// DeleteContext - Delete with request ID of ctx in audit trail
+func (at *AccessToken) DeleteContext(ctx context.Context, d *sqlx.DB, creatorID int64, softDelete bool) error {
 	query, val := db.PrepareDelete(table, at.ID, softDelete)
 	_, _, err := db.Exec(d, query, val)
 	if err == nil {
@@ -87,7 +113,7 @@ func (at *AccessToken) Delete(d *sqlx.DB, creatorID int64, softDelete bool) erro
 			if !softDelete {
 				remark = "permanent delete"
 			}
-			event.CreateAuditTrail(map[string]interface{}{
+			event.CreateAuditTrailContext(ctx, map[string]interface{}{
 				"operation":   "edit",
 				"module_name": moduleName,
 				"table_name":  table,
@@ -133,57 +159,35 @@ func (at *AccessToken) GetByDeviceToken(d *sqlx.DB, token string) (bool, error)
 
 // List -
 func List(d *sqlx.DB, params map[string]interface{}, orderParams map[string]interface{}) ([]AccessToken, int64, error) {
+	return ListFilter(d, listFilters, params, orderParams)
+}
+
+// ListFilter - List with custom filter configs
+func ListFilter(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}) ([]AccessToken, int64, error) {
 	list := []AccessToken{}
-	values := map[string]interface{}{}
-	condition := "AND deleted_at IS NULL "
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "account_id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "client_id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "user_id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "is_revoke",
-		Condition: "equal",
-	}, params, condition, values)
-
-	defaultOrder := map[string]interface{}{
-		"field":     "created_at",
-		"direction": "asc",
-		"start":     int64(0),
-		"limit":     int64(10),
-	}
+	totalItems, err := db.SelectPage(d, &list, listConfig(filters), params, orderParams)
+	return list, totalItems, err
+}
 
-	type pagination struct {
-		TotalItems int64 `db:"total"`
-	}
-	p := new(pagination)
+// Paginate - List with custom filter configs into pagination, formatter receive *AccessToken
+func Paginate(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}, formatter db.Formatter) (*libresponse.Pagination, error) {
+	list := []AccessToken{}
+	return db.Paginate(d, &list, listConfig(filters), params, orderParams, formatter)
+}
 
-	query := "SELECT COUNT(" + table + ".id) AS total FROM " + table + " WHERE 1=1 " + condition
-	_, err := db.Get(d, p, query, values)
-	if err != nil {
-		return list, 0, err
+// listConfig - List query config of table
+func listConfig(filters []libquery.Config) db.ListConfig {
+	return db.ListConfig{
+		Table:     table,
+		Condition: "AND deleted_at IS NULL ",
+		Filters:   filters,
+		DefaultOrder: map[string]interface{}{
+			"field":     "created_at",
+			"direction": "asc",
+			"start":     int64(0),
+			"limit":     int64(10),
+		},
 	}
-
-	orderCondition := db.PrepareOrder(orderParams, defaultOrder)
-	query = "SELECT * FROM " + table + " WHERE 1=1 " + condition + orderCondition
-	err = db.Select(d, &list, query, values)
-	return list, p.TotalItems, err
 }
 
 // ListActiveDeviceToken -
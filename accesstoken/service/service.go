@@ -0,0 +1,63 @@
+package service
+
+import (
+	"github.com/helloferdie/stdgo/accesstoken"
+	"github.com/helloferdie/stdgo/db"
+	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
+	"github.com/helloferdie/stdgo/libvalidator"
+)
+
+// FormatOutput - Access token output without refresh token
+func FormatOutput(obj *accesstoken.AccessToken, format map[string]interface{}) map[string]interface{} {
+	m := libresponse.MapOutput(obj, true, format)
+	delete(m, "refresh_token")
+	return m
+}
+
+// ListRequest -
+type ListRequest struct {
+	Page         int64  `json:"page" loc:"general" validate:"required,numeric,min=1"`
+	ItemsPerPage int64  `json:"items_per_page" loc:"general" validate:"required,numeric,min=1,max=500"`
+	OrderByField string `json:"order_by_field" loc:"general"`
+	OrderByDir   string `json:"order_by_direction" loc:"general"`
+	ID           string `json:"id" loc:"general" filter:"equal"`
+	AccountID    string `json:"account_id" loc:"auth" validate:"omitempty,numeric" filter:"equal"`
+	ClientID     string `json:"client_id" loc:"auth" validate:"omitempty,numeric" filter:"equal"`
+	UserID       string `json:"user_id" loc:"auth" validate:"omitempty,numeric" filter:"equal"`
+	IsRevoke     string `json:"is_revoke" loc:"auth" validate:"omitempty,oneof=0 1" filter:"equal"`
+}
+
+// List -
+func List(r *ListRequest, format map[string]interface{}) *libresponse.Default {
+	res, err := libvalidator.Validate(r)
+	if err != nil {
+		return res
+	}
+
+	d, _ := db.Open("")
+	defer d.Close()
+
+	tz, _ := format["tz"].(string)
+	filters, params := libquery.Filters(r, tz)
+	orderParams := map[string]interface{}{
+		"field":     r.OrderByField,
+		"direction": r.OrderByDir,
+		"start":     ((r.Page - 1) * r.ItemsPerPage),
+		"limit":     r.ItemsPerPage,
+	}
+
+	p, err := accesstoken.Paginate(d, filters, params, orderParams, func(obj interface{}) interface{} {
+		return FormatOutput(obj.(*accesstoken.AccessToken), format)
+	})
+	if ve, ok := err.(*libquery.ValidationError); ok {
+		res = ve.Response()
+	} else if err != nil {
+		res.Code = 500
+		res.Message = "general.error_internal"
+		res.Error = "general.error_list"
+	} else {
+		res.SuccessList(p)
+	}
+	return res
+}
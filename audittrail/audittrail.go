@@ -9,6 +9,7 @@ import (
 	"github.com/helloferdie/stdgo/db"
 
 	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/sony/sonyflake"
@@ -30,6 +31,19 @@ type AuditTrail struct {
 
 var moduleName = "audit_trail"
 var table = "audit_trails"
+
+// listFilters - Default filters of List
+var listFilters = []libquery.Config{
+	{Param: "id", Condition: "equal"},
+	{Param: "module_name", Condition: "like"},
+	{Param: "table_name", Condition: "like"},
+	{Param: "table_pk", Condition: "like"},
+	{Param: "operation", Condition: "like"},
+	{Param: "change", Condition: "like"},
+	{Param: "remark", Condition: "like"},
+	{Param: "created_by", Condition: "equal"},
+}
+
 var sf *sonyflake.Sonyflake
 
 func init() {
@@ -66,72 +80,35 @@ func (at *AuditTrail) Create(d *sqlx.DB) (string, error) {
 
 // List -
 func List(d *sqlx.DB, params map[string]interface{}, orderParams map[string]interface{}) ([]AuditTrail, int64, error) {
+	return ListFilter(d, listFilters, params, orderParams)
+}
+
+// ListFilter - List with custom filter configs
+func ListFilter(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}) ([]AuditTrail, int64, error) {
 	list := []AuditTrail{}
-	values := map[string]interface{}{}
-	condition := " "
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "module_name",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "table_name",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "table_pk",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "operation",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "change",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "remark",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "created_by",
-		Condition: "equal",
-	}, params, condition, values)
-
-	defaultOrder := map[string]interface{}{
-		"field":     "created_at",
-		"direction": "desc",
-		"start":     int64(0),
-		"limit":     int64(10),
-	}
+	totalItems, err := db.SelectPage(d, &list, listConfig(filters), params, orderParams)
+	return list, totalItems, err
+}
 
-	type pagination struct {
-		TotalItems int64 `db:"total"`
-	}
-	p := new(pagination)
+// Paginate - List with custom filter configs into pagination, formatter receive *AuditTrail
+func Paginate(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}, formatter db.Formatter) (*libresponse.Pagination, error) {
+	list := []AuditTrail{}
+	return db.Paginate(d, &list, listConfig(filters), params, orderParams, formatter)
+}
 
-	query := "SELECT COUNT(" + table + ".id) AS total FROM " + table + " WHERE 1=1 " + condition
-	_, err := db.Get(d, p, query, values)
-	if err != nil {
-		return list, 0, err
+// listConfig - List query config of table
+func listConfig(filters []libquery.Config) db.ListConfig {
+	return db.ListConfig{
+		Table:     table,
+		Condition: " ",
+		Filters:   filters,
+		DefaultOrder: map[string]interface{}{
+			"field":     "created_at",
+			"direction": "desc",
+			"start":     int64(0),
+			"limit":     int64(10),
+		},
 	}
-
-	orderCondition := db.PrepareOrder(orderParams, defaultOrder)
-	query = "SELECT * FROM " + table + " WHERE 1=1 " + condition + orderCondition
-	err = db.Select(d, &list, query, values)
-	return list, p.TotalItems, err
 }
 
 // GetByID -
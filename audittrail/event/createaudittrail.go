@@ -1,6 +1,7 @@
 package event
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -15,18 +16,23 @@ import (
 // CreateAuditTrailAppID -
 var CreateAuditTrailAppID = "create-audit-trail"
 
-// CreateAuditTrail -
+// CreateAuditTrail - Create audit trail without request ID, use CreateAuditTrailContext with c.Request().Context() in echo handler
 func CreateAuditTrail(payload map[string]interface{}) {
+	CreateAuditTrailContext(context.Background(), payload)
+}
+
+// CreateAuditTrailContext - Create audit trail with request ID of ctx in message header, ctx is only read for request ID
+func CreateAuditTrailContext(ctx context.Context, payload map[string]interface{}) {
 	mode := os.Getenv("audit_trail_mode")
 
 	if mode == "db" {
 
 	} else {
 		r := resource.Get()
-		err := librabbitmq.Publish(r, CreateAuditTrailAppID, payload)
+		err := librabbitmq.PublishContext(ctx, r, CreateAuditTrailAppID, payload)
 		if err != nil {
 			// Do failover here
-			resp, respCode, err := libhttp.RequestAuditTrails(payload)
+			resp, respCode, err := libhttp.RequestAuditTrailsContext(ctx, payload)
 			if err != nil || respCode != 200 {
 				d, err := db.Open("")
 				if err == nil {
@@ -1,12 +1,12 @@
 package service
 
 import (
-	"math"
 	"strings"
 
 	"github.com/helloferdie/stdgo/audittrail"
 	"github.com/helloferdie/stdgo/db"
 	"github.com/helloferdie/stdgo/libencryption"
+	"github.com/helloferdie/stdgo/libquery"
 	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libslice"
 	"github.com/helloferdie/stdgo/libtime"
@@ -27,14 +27,14 @@ type ListRequest struct {
 	OrderByField     string `json:"order_by_field" loc:"general"`
 	OrderByDir       string `json:"order_by_direction" loc:"general"`
 	ShowRelationship bool   `json:"show_relationship" loc:"general"`
-	ID               string `json:"id" loc:"general"`
-	Operation        string `json:"operation" loc:"audit"`
-	ModuleName       string `json:"module_name" loc:"audit"`
-	TableName        string `json:"table_name" loc:"audit"`
-	TablePK          string `json:"table_pk" loc:"audit"`
-	Change           string `json:"change" loc:"audit"`
-	Remark           string `json:"remark" loc:"audit"`
-	CreatedBy        string `json:"created_by" loc:"audit"`
+	ID               string `json:"id" loc:"general" filter:"equal"`
+	Operation        string `json:"operation" loc:"audit" filter:"like"`
+	ModuleName       string `json:"module_name" loc:"audit" filter:"like,column=module_name"`
+	TableName        string `json:"table_name" loc:"audit" filter:"like"`
+	TablePK          string `json:"table_pk" loc:"audit" filter:"like"`
+	Change           string `json:"change" loc:"audit" filter:"like"`
+	Remark           string `json:"remark" loc:"audit" filter:"like"`
+	CreatedBy        string `json:"created_by" loc:"audit" filter:"equal"`
 }
 
 // List -
@@ -47,16 +47,8 @@ func List(r *ListRequest, format map[string]interface{}) *libresponse.Default {
 	d, _ := db.Open("")
 	defer d.Close()
 
-	params := map[string]interface{}{
-		"id":          r.ID,
-		"operation":   r.Operation,
-		"module_name": r.ModuleName,
-		"table_name":  r.TableName,
-		"table_pk":    r.TablePK,
-		"change":      r.Change,
-		"remark":      r.Remark,
-		"created_by":  r.CreatedBy,
-	}
+	tz, _ := format["tz"].(string)
+	filters, params := libquery.Filters(r, tz)
 	orderParams := map[string]interface{}{
 		"field":     r.OrderByField,
 		"direction": r.OrderByDir,
@@ -64,26 +56,18 @@ func List(r *ListRequest, format map[string]interface{}) *libresponse.Default {
 		"limit":     r.ItemsPerPage,
 	}
 
-	list, totalItems, err := audittrail.List(d, params, orderParams)
-	if err != nil {
+	format["show_relationship"] = r.ShowRelationship
+	p, err := audittrail.Paginate(d, filters, params, orderParams, func(obj interface{}) interface{} {
+		return FormatOutput(obj.(*audittrail.AuditTrail), format)
+	})
+	if ve, ok := err.(*libquery.ValidationError); ok {
+		res = ve.Response()
+	} else if err != nil {
 		res.Code = 500
 		res.Message = "general.error_internal"
 		res.Error = "general.error_list"
 	} else {
-		tmp := make([]interface{}, len(list))
-		format["show_relationship"] = r.ShowRelationship
-		for k, obj := range list {
-			tmp[k] = FormatOutput(&obj, format)
-		}
-		res.Success = true
-		res.Code = 200
-		res.Message = "general.success_list"
-		totalPages := math.Ceil(float64(totalItems) / float64(r.ItemsPerPage))
-		res.Data = map[string]interface{}{
-			"items":       tmp,
-			"total_items": totalItems,
-			"total_pages": totalPages,
-		}
+		res.SuccessList(p)
 	}
 	return res
 }
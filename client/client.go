@@ -1,12 +1,14 @@
 package client
 
 import (
+	"context"
 	"database/sql"
 	"strconv"
 
 	"github.com/helloferdie/stdgo/audittrail/event"
 	"github.com/helloferdie/stdgo/db"
 	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libstring"
 	"github.com/jmoiron/sqlx"
 )
@@ -26,13 +28,26 @@ type Client struct {
 var moduleName = "client"
 var table = "clients"
 
-// Create -
+// listFilters - Default filters of List
+var listFilters = []libquery.Config{
+	{Param: "id", Condition: "equal"},
+	{Param: "client_name", Condition: "like"},
+	{Param: "client_secret", Condition: "like"},
+	{Param: "uuid", Condition: "like"},
+}
+
+// Create - Create without request ID in audit trail, use CreateContext with c.Request().Context() in echo handler
 func (cl *Client) Create(d *sqlx.DB, creatorID int64) (int64, error) {
+	return cl.CreateContext(context.Background(), d, creatorID)
+}
+
+// CreateContext - Create with request ID of ctx in audit trail
+func (cl *Client) CreateContext(ctx context.Context, d *sqlx.DB, creatorID int64) (int64, error) {
 	query, val := db.PrepareInsert(table, cl, []string{})
 	id, _, err := db.Exec(d, query, val)
 	if err == nil {
 		cl.GetByID(d, id)
-		go event.CreateAuditTrail(map[string]interface{}{
+		go event.CreateAuditTrailContext(ctx, map[string]interface{}{
 			"operation":   "add",
 			"module_name": moduleName,
 			"table_name":  table,
@@ -45,8 +60,13 @@ func (cl *Client) Create(d *sqlx.DB, creatorID int64) (int64, error) {
 	return id, err
 }
 
-// Save -
+// Save - Save without request ID in audit trail, use SaveContext with c.Request().Context() in echo handler
 func (cl *Client) Save(d *sqlx.DB, creatorID int64) error {
+	return cl.SaveContext(context.Background(), d, creatorID)
+}
+
+// SaveContext - Save with request ID of ctx in audit trail
+func (cl *Client) SaveContext(ctx context.Context, d *sqlx.DB, creatorID int64) error {
 	old := new(Client)
 	old.GetByID(d, cl.ID)
 
@@ -54,7 +74,7 @@ func (cl *Client) Save(d *sqlx.DB, creatorID int64) error {
 	if len(diff) > 0 {
 		_, _, err := db.Exec(d, query, val)
 		if err == nil {
-			go event.CreateAuditTrail(map[string]interface{}{
+			go event.CreateAuditTrailContext(ctx, map[string]interface{}{
 				"operation":   "edit",
 				"module_name": moduleName,
 				"table_name":  table,
@@ -69,8 +89,13 @@ func (cl *Client) Save(d *sqlx.DB, creatorID int64) error {
 	return nil
 }
 
-// Delete -
+// Delete - Delete without request ID in audit trail, use DeleteContext with c.Request().Context() in echo handler
 func (cl *Client) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
+	return cl.DeleteContext(context.Background(), d, creatorID, softDelete)
+}
+
+// DeleteContext - Delete with request ID of ctx in audit trail
+func (cl *Client) DeleteContext(ctx context.Context, d *sqlx.DB, creatorID int64, softDelete bool) error {
 	query, val := db.PrepareDelete(table, cl.ID, softDelete)
 	_, _, err := db.Exec(d, query, val)
 	if err == nil {
@@ -79,7 +104,7 @@ func (cl *Client) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
 			if !softDelete {
 				remark = "permanent delete"
 			}
-			event.CreateAuditTrail(map[string]interface{}{
+			event.CreateAuditTrailContext(ctx, map[string]interface{}{
 				"operation":   "edit",
 				"module_name": moduleName,
 				"table_name":  table,
@@ -95,52 +120,35 @@ func (cl *Client) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
 
 // List -
 func List(d *sqlx.DB, params map[string]interface{}, orderParams map[string]interface{}) ([]Client, int64, error) {
+	return ListFilter(d, listFilters, params, orderParams)
+}
+
+// ListFilter - List with custom filter configs
+func ListFilter(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}) ([]Client, int64, error) {
 	list := []Client{}
-	values := map[string]interface{}{}
-	condition := "AND deleted_at IS NULL "
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "client_name",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "client_secret",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "uuid",
-		Condition: "like",
-	}, params, condition, values)
-
-	defaultOrder := map[string]interface{}{
-		"field":     "uuid",
-		"direction": "asc",
-		"start":     int64(0),
-		"limit":     int64(10),
-	}
+	totalItems, err := db.SelectPage(d, &list, listConfig(filters), params, orderParams)
+	return list, totalItems, err
+}
 
-	type pagination struct {
-		TotalItems int64 `db:"total"`
-	}
-	p := new(pagination)
+// Paginate - List with custom filter configs into pagination, formatter receive *Client
+func Paginate(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}, formatter db.Formatter) (*libresponse.Pagination, error) {
+	list := []Client{}
+	return db.Paginate(d, &list, listConfig(filters), params, orderParams, formatter)
+}
 
-	query := "SELECT COUNT(" + table + ".id) AS total FROM " + table + " WHERE 1=1 " + condition
-	_, err := db.Get(d, p, query, values)
-	if err != nil {
-		return list, 0, err
+// listConfig - List query config of table
+func listConfig(filters []libquery.Config) db.ListConfig {
+	return db.ListConfig{
+		Table:     table,
+		Condition: "AND deleted_at IS NULL ",
+		Filters:   filters,
+		DefaultOrder: map[string]interface{}{
+			"field":     "uuid",
+			"direction": "asc",
+			"start":     int64(0),
+			"limit":     int64(10),
+		},
 	}
-
-	orderCondition := db.PrepareOrder(orderParams, defaultOrder)
-	query = "SELECT * FROM " + table + " WHERE 1=1 " + condition + orderCondition
-	err = db.Select(d, &list, query, values)
-	return list, p.TotalItems, err
 }
 
 // GetByID -
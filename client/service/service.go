@@ -3,10 +3,63 @@ package service
 import (
 	"github.com/helloferdie/stdgo/client"
 	"github.com/helloferdie/stdgo/db"
+	"github.com/helloferdie/stdgo/libquery"
 	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libvalidator"
 )
 
+// FormatOutput - Client output without secret
+func FormatOutput(obj *client.Client, format map[string]interface{}) map[string]interface{} {
+	m := libresponse.MapOutput(obj, true, format)
+	delete(m, "client_secret")
+	return m
+}
+
+// ListRequest -
+type ListRequest struct {
+	Page         int64  `json:"page" loc:"general" validate:"required,numeric,min=1"`
+	ItemsPerPage int64  `json:"items_per_page" loc:"general" validate:"required,numeric,min=1,max=500"`
+	OrderByField string `json:"order_by_field" loc:"general"`
+	OrderByDir   string `json:"order_by_direction" loc:"general"`
+	ID           string `json:"id" loc:"general" validate:"omitempty,numeric" filter:"equal"`
+	UUID         string `json:"uuid" loc:"auth" filter:"like"`
+	ClientName   string `json:"client_name" loc:"auth" filter:"like"`
+}
+
+// List -
+func List(r *ListRequest, format map[string]interface{}) *libresponse.Default {
+	res, err := libvalidator.Validate(r)
+	if err != nil {
+		return res
+	}
+
+	d, _ := db.Open("")
+	defer d.Close()
+
+	tz, _ := format["tz"].(string)
+	filters, params := libquery.Filters(r, tz)
+	orderParams := map[string]interface{}{
+		"field":     r.OrderByField,
+		"direction": r.OrderByDir,
+		"start":     ((r.Page - 1) * r.ItemsPerPage),
+		"limit":     r.ItemsPerPage,
+	}
+
+	p, err := client.Paginate(d, filters, params, orderParams, func(obj interface{}) interface{} {
+		return FormatOutput(obj.(*client.Client), format)
+	})
+	if ve, ok := err.(*libquery.ValidationError); ok {
+		res = ve.Response()
+	} else if err != nil {
+		res.Code = 500
+		res.Message = "general.error_internal"
+		res.Error = "general.error_list"
+	} else {
+		res.SuccessList(p)
+	}
+	return res
+}
+
 // GetRequest -
 type GetRequest struct {
 	ClientUUID   string `json:"client_uuid" loc:"auth" validate:"required"`
@@ -0,0 +1,60 @@
+// Command localecheck report locale key missing or extra relative to default locale,
+// placeholder mismatch between translation and key used in Go source without translation.
+//
+//	localecheck -dir ./locale -default en -src .
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/helloferdie/stdgo/liblocale"
+)
+
+func main() {
+	defaultLang := os.Getenv("default_locale")
+	if defaultLang == "" {
+		defaultLang = "en"
+	}
+	dir := flag.String("dir", os.Getenv("dir_locale"), "locale directory of <lang>/<namespace>.json")
+	def := flag.String("default", defaultLang, "default locale")
+	src := flag.String("src", "", "Go source root to scan for locale key, empty to skip")
+	asJSON := flag.Bool("json", false, "print report in JSON")
+	flag.Parse()
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "locale directory is required, set -dir or dir_locale")
+		os.Exit(2)
+	}
+	catalog, err := liblocale.NewCatalog(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	issues := liblocale.Check(catalog, *def)
+	if *src != "" {
+		keys, err := liblocale.ScanSource(*src)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		issues = append(issues, liblocale.CheckSource(catalog, *def, keys)...)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(issues)
+	} else {
+		for _, i := range issues {
+			fmt.Println(i)
+		}
+		fmt.Printf("%d issue(s)\n", len(issues))
+	}
+	if len(issues) > 0 {
+		os.Exit(1)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/helloferdie/stdgo/libmetrics"
 	"github.com/helloferdie/stdgo/libslice"
 	"github.com/helloferdie/stdgo/logger"
 
@@ -17,6 +18,17 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+var metricQueries = libmetrics.NewCounter("db_queries_total", "Total database queries", "operation", "result")
+
+// metricQuery - Record query result by operation
+func metricQuery(operation string, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metricQueries.Inc(operation, result)
+}
+
 // ConnectionString -
 func ConnectionString() string {
 	conn := "default:default@(127.0.0.1:3306)/golang?timeout=10s&charset=utf8mb4&parseTime=true"
@@ -91,6 +103,7 @@ func OpenRetry(conn string, maxRetry int) (*sqlx.DB, error) {
 // Exec -
 func Exec(db *sqlx.DB, query string, values map[string]interface{}) (int64, int64, error) {
 	result, err := db.NamedExec(query, values)
+	metricQuery("exec", err)
 	if err != nil {
 		logger.MakeLogEntry(nil, true).Errorf("Error execute query %v", err)
 		return 0, 0, err
@@ -153,6 +166,7 @@ func ExecList(db *sqlx.DB, list []interface{}) error {
 func Get(db *sqlx.DB, list interface{}, query string, values map[string]interface{}) (bool, error) {
 	exist := false
 	rows, err := db.NamedQuery(query, values)
+	metricQuery("get", err)
 	if err != nil {
 		logger.MakeLogEntry(nil, true).Errorf("Error get query %v", err)
 		return exist, err
@@ -179,6 +193,7 @@ func Select(db *sqlx.DB, list interface{}, query string, values map[string]inter
 		return err
 	}
 	err = nstmt.Select(list, values)
+	metricQuery("select", err)
 	if err != nil {
 		logger.MakeLogEntry(nil, true).Errorf("Error select query %v", err)
 		return err
@@ -345,13 +360,13 @@ func PrepareDelete(table string, pk interface{}, softDelete bool) (string, map[s
 	return query, v
 }
 
-// PrepareOrder -
+// PrepareOrder - Build ORDER BY and LIMIT, custom order is only read from trusted def
 func PrepareOrder(params map[string]interface{}, def map[string]interface{}) string {
 	query := ""
 	orderVal, orderExist := params["field"].(string)
 	defVal, _ := def["field"].(string)
 	customOrder := false
-	if orderExist && orderVal != "" {
+	if orderExist && orderVal != "" && !strings.Contains(orderVal, "`") {
 		query += "ORDER BY `" + orderVal + "` "
 	} else {
 		defCustomVal, _ := def["custom"].(string)
@@ -364,10 +379,10 @@ func PrepareOrder(params map[string]interface{}, def map[string]interface{}) str
 	}
 
 	if !customOrder {
-		orderVal, orderExist = params["direction"].(string)
+		orderVal, _ = params["direction"].(string)
 		defVal, _ = def["direction"].(string)
-		if orderExist && orderVal != "" {
-			query += orderVal + " "
+		if dir := strings.ToUpper(orderVal); dir == "ASC" || dir == "DESC" {
+			query += dir + " "
 		} else {
 			query += defVal + " "
 		}
@@ -0,0 +1,57 @@
+package db
+
+import "testing"
+
+func TestPrepareOrder(t *testing.T) {
+	def := map[string]interface{}{"field": "id", "direction": "DESC", "start": int64(0), "limit": int64(10)}
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		def    map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "field and direction",
+			params: map[string]interface{}{"field": "label", "direction": "asc", "start": int64(20), "limit": int64(10)},
+			def:    def,
+			want:   "ORDER BY `label` ASC LIMIT 20, 10 ",
+		},
+		{
+			name:   "default",
+			params: map[string]interface{}{},
+			def:    def,
+			want:   "ORDER BY `id` DESC LIMIT 0, 10 ",
+		},
+		{
+			name:   "custom of params ignored",
+			params: map[string]interface{}{"custom": "ORDER BY (SELECT 1)", "show": true},
+			def:    def,
+			want:   "ORDER BY `id` DESC ",
+		},
+		{
+			name:   "custom of default",
+			params: map[string]interface{}{"show": true},
+			def:    map[string]interface{}{"custom": "ORDER BY `a` ASC, `b` DESC"},
+			want:   "ORDER BY `a` ASC, `b` DESC ",
+		},
+		{
+			name:   "field with backtick",
+			params: map[string]interface{}{"field": "id` DESC, (SELECT 1) -- ", "show": true},
+			def:    def,
+			want:   "ORDER BY `id` DESC ",
+		},
+		{
+			name:   "invalid direction",
+			params: map[string]interface{}{"field": "label", "direction": "ASC, (SELECT 1)", "show": true},
+			def:    def,
+			want:   "ORDER BY `label` DESC ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrepareOrder(tt.params, tt.def); got != tt.want {
+				t.Errorf("PrepareOrder() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
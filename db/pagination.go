@@ -0,0 +1,129 @@
+package db
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ListConfig - Config for paginated list query
+type ListConfig struct {
+	Table        string
+	Condition    string // Base condition, e.g. "AND deleted_at IS NULL "
+	Filters      []libquery.Config
+	Where        libquery.Node // Condition tree appended after filters
+	DefaultOrder map[string]interface{}
+	Concurrent   bool // Run count and page query concurrently
+}
+
+// Formatter - Format single row of list into output item
+type Formatter func(obj interface{}) interface{}
+
+// SelectPage - Run count and page query of table, scan rows into list and return total items
+func SelectPage(d *sqlx.DB, list interface{}, cfg ListConfig, params map[string]interface{}, orderParams map[string]interface{}) (int64, error) {
+	values := map[string]interface{}{}
+	condition := cfg.Condition
+	for _, f := range cfg.Filters {
+		var err error
+		condition, values, err = libquery.QueryCondition(f, params, condition, values)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if cfg.Where != nil {
+		var err error
+		condition, values, err = libquery.Build(cfg.Where, condition, values)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	type pagination struct {
+		TotalItems int64 `db:"total"`
+	}
+	p := new(pagination)
+
+	countQuery := "SELECT COUNT(" + cfg.Table + ".id) AS total FROM " + cfg.Table + " WHERE 1=1 " + condition
+	orderCondition := PrepareOrder(orderParams, cfg.DefaultOrder)
+	pageQuery := "SELECT * FROM " + cfg.Table + " WHERE 1=1 " + condition + orderCondition
+
+	if !cfg.Concurrent {
+		_, err := Get(d, p, countQuery, values)
+		if err != nil {
+			return 0, err
+		}
+		err = Select(d, list, pageQuery, values)
+		return p.TotalItems, err
+	}
+
+	var wg sync.WaitGroup
+	var errCount, errPage error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, errCount = Get(d, p, countQuery, values)
+	}()
+	go func() {
+		defer wg.Done()
+		errPage = Select(d, list, pageQuery, values)
+	}()
+	wg.Wait()
+
+	if errCount != nil {
+		return 0, errCount
+	}
+	return p.TotalItems, errPage
+}
+
+// Paginate - Run paginated list query and format rows into pagination response
+func Paginate(d *sqlx.DB, list interface{}, cfg ListConfig, params map[string]interface{}, orderParams map[string]interface{}, formatter Formatter) (*libresponse.Pagination, error) {
+	totalItems, err := SelectPage(d, list, cfg, params, orderParams)
+	if err != nil {
+		return nil, err
+	}
+
+	rVal := reflect.ValueOf(list)
+	if rVal.Kind() == reflect.Ptr {
+		rVal = rVal.Elem()
+	}
+	items := make([]interface{}, rVal.Len())
+	for i := 0; i < rVal.Len(); i++ {
+		obj := rVal.Index(i)
+		if obj.CanAddr() {
+			obj = obj.Addr()
+		}
+		if formatter != nil {
+			items[i] = formatter(obj.Interface())
+		} else {
+			items[i] = obj.Interface()
+		}
+	}
+
+	page, itemsPerPage := PageFromOrder(orderParams, cfg.DefaultOrder, totalItems)
+	return libresponse.NewPagination(items, totalItems, page, itemsPerPage), nil
+}
+
+// PageFromOrder - Return page and items per page from order params start and limit
+func PageFromOrder(params map[string]interface{}, def map[string]interface{}, totalItems int64) (int64, int64) {
+	showVal, _ := params["show"].(bool)
+	if showVal {
+		return 1, totalItems
+	}
+
+	start, ok := params["start"].(int64)
+	if !ok {
+		start, _ = def["start"].(int64)
+	}
+	limit, ok := params["limit"].(int64)
+	if !ok {
+		limit, _ = def["limit"].(int64)
+	}
+	if limit <= 0 {
+		return 1, limit
+	}
+	return start/limit + 1, limit
+}
@@ -0,0 +1,26 @@
+package db
+
+import "testing"
+
+func TestPageFromOrder(t *testing.T) {
+	def := map[string]interface{}{"start": int64(0), "limit": int64(10)}
+	tests := []struct {
+		name         string
+		params       map[string]interface{}
+		page         int64
+		itemsPerPage int64
+	}{
+		{"first page", map[string]interface{}{"start": int64(0), "limit": int64(25)}, 1, 25},
+		{"third page", map[string]interface{}{"start": int64(50), "limit": int64(25)}, 3, 25},
+		{"default", map[string]interface{}{}, 1, 10},
+		{"show all", map[string]interface{}{"show": true, "start": int64(50), "limit": int64(25)}, 1, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, itemsPerPage := PageFromOrder(tt.params, def, 42)
+			if page != tt.page || itemsPerPage != tt.itemsPerPage {
+				t.Errorf("PageFromOrder() = %d, %d, want %d, %d", page, itemsPerPage, tt.page, tt.itemsPerPage)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package language
 
 import (
+	"context"
 	"database/sql"
 
 	"strconv"
@@ -8,6 +9,7 @@ import (
 	"github.com/helloferdie/stdgo/audittrail/event"
 	"github.com/helloferdie/stdgo/db"
 	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libstring"
 	"github.com/jmoiron/sqlx"
 )
@@ -25,13 +27,25 @@ type Language struct {
 var moduleName = "language"
 var table = "languages"
 
-// Create -
+// listFilters - Default filters of List
+var listFilters = []libquery.Config{
+	{Param: "id", Condition: "equal"},
+	{Param: "label", Condition: "like"},
+	{Param: "label_short", Condition: "like"},
+}
+
+// Create - Create without request ID in audit trail, use CreateContext with c.Request().Context() in echo handler
 func (la *Language) Create(d *sqlx.DB, creatorID int64) (int64, error) {
+	return la.CreateContext(context.Background(), d, creatorID)
+}
+
+// CreateContext - Create with request ID of ctx in audit trail
+func (la *Language) CreateContext(ctx context.Context, d *sqlx.DB, creatorID int64) (int64, error) {
 	query, val := db.PrepareInsert(table, la, []string{})
 	id, _, err := db.Exec(d, query, val)
 	if err == nil {
 		la.GetByID(d, id)
-		go event.CreateAuditTrail(map[string]interface{}{
+		go event.CreateAuditTrailContext(ctx, map[string]interface{}{
 			"operation":   "add",
 			"module_name": moduleName,
 			"table_name":  table,
@@ -44,8 +58,13 @@ func (la *Language) Create(d *sqlx.DB, creatorID int64) (int64, error) {
 	return id, err
 }
 
-// Save -
+// Save - Save without request ID in audit trail, use SaveContext with c.Request().Context() in echo handler
 func (la *Language) Save(d *sqlx.DB, creatorID int64) error {
+	return la.SaveContext(context.Background(), d, creatorID)
+}
+
+// SaveContext - Save with request ID of ctx in audit trail
+func (la *Language) SaveContext(ctx context.Context, d *sqlx.DB, creatorID int64) error {
 	old := new(Language)
 	old.GetByID(d, la.ID)
 
@@ -53,7 +72,7 @@ func (la *Language) Save(d *sqlx.DB, creatorID int64) error {
 	if len(diff) > 0 {
 		_, _, err := db.Exec(d, query, val)
 		if err == nil {
-			go event.CreateAuditTrail(map[string]interface{}{
+			go event.CreateAuditTrailContext(ctx, map[string]interface{}{
 				"operation":   "edit",
 				"module_name": moduleName,
 				"table_name":  table,
@@ -68,8 +87,13 @@ func (la *Language) Save(d *sqlx.DB, creatorID int64) error {
 	return nil
 }
 
-// Delete -
+// Delete - Delete without request ID in audit trail, use DeleteContext with c.Request().Context() in echo handler
 func (la *Language) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
+	return la.DeleteContext(context.Background(), d, creatorID, softDelete)
+}
+
+// DeleteContext - Delete with request ID of ctx in audit trail
+func (la *Language) DeleteContext(ctx context.Context, d *sqlx.DB, creatorID int64, softDelete bool) error {
 	query, val := db.PrepareDelete(table, la.ID, softDelete)
 	_, _, err := db.Exec(d, query, val)
 	if err == nil {
@@ -78,7 +102,7 @@ func (la *Language) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
 			if !softDelete {
 				remark = "permanent delete"
 			}
-			event.CreateAuditTrail(map[string]interface{}{
+			event.CreateAuditTrailContext(ctx, map[string]interface{}{
 				"operation":   "edit",
 				"module_name": moduleName,
 				"table_name":  table,
@@ -94,47 +118,35 @@ func (la *Language) Delete(d *sqlx.DB, creatorID int64, softDelete bool) error {
 
 // List -
 func List(d *sqlx.DB, params map[string]interface{}, orderParams map[string]interface{}) ([]Language, int64, error) {
-	list := []Language{}
-	values := map[string]interface{}{}
-	condition := "AND deleted_at IS NULL "
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "id",
-		Condition: "equal",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "label",
-		Condition: "like",
-	}, params, condition, values)
-
-	condition, values, _ = libquery.QueryCondition(libquery.Config{
-		Param:     "label_short",
-		Condition: "like",
-	}, params, condition, values)
+	return ListFilter(d, listFilters, params, orderParams)
+}
 
-	defaultOrder := map[string]interface{}{
-		"field":     "label",
-		"direction": "asc",
-		"start":     int64(0),
-		"limit":     int64(10),
-	}
+// ListFilter - List with custom filter configs
+func ListFilter(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}) ([]Language, int64, error) {
+	list := []Language{}
+	totalItems, err := db.SelectPage(d, &list, listConfig(filters), params, orderParams)
+	return list, totalItems, err
+}
 
-	type pagination struct {
-		TotalItems int64 `db:"total"`
-	}
-	p := new(pagination)
+// Paginate - List with custom filter configs into pagination, formatter receive *Language
+func Paginate(d *sqlx.DB, filters []libquery.Config, params map[string]interface{}, orderParams map[string]interface{}, formatter db.Formatter) (*libresponse.Pagination, error) {
+	list := []Language{}
+	return db.Paginate(d, &list, listConfig(filters), params, orderParams, formatter)
+}
 
-	query := "SELECT COUNT(" + table + ".id) AS total FROM " + table + " WHERE 1=1 " + condition
-	_, err := db.Get(d, p, query, values)
-	if err != nil {
-		return list, 0, err
+// listConfig - List query config of table
+func listConfig(filters []libquery.Config) db.ListConfig {
+	return db.ListConfig{
+		Table:     table,
+		Condition: "AND deleted_at IS NULL ",
+		Filters:   filters,
+		DefaultOrder: map[string]interface{}{
+			"field":     "label",
+			"direction": "asc",
+			"start":     int64(0),
+			"limit":     int64(10),
+		},
 	}
-
-	orderCondition := db.PrepareOrder(orderParams, defaultOrder)
-	query = "SELECT * FROM " + table + " WHERE 1=1 " + condition + orderCondition
-	err = db.Select(d, &list, query, values)
-	return list, p.TotalItems, err
 }
 
 // GetByID -
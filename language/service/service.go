@@ -1,10 +1,9 @@
 package service
 
 import (
-	"math"
-
 	"github.com/helloferdie/stdgo/db"
 	"github.com/helloferdie/stdgo/language"
+	"github.com/helloferdie/stdgo/libquery"
 	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libslice"
 	"github.com/helloferdie/stdgo/libvalidator"
@@ -23,9 +22,9 @@ type ListRequest struct {
 	OrderByField     string `json:"order_by_field" loc:"general"`
 	OrderByDir       string `json:"order_by_direction" loc:"general"`
 	ShowRelationship bool   `json:"show_relationship" loc:"general"`
-	ID               string `json:"id" loc:"general" validate:"omitempty,numeric"`
-	Label            string `json:"label" loc:"language"`
-	LabelShort       string `json:"label_short" loc:"language"`
+	ID               string `json:"id" loc:"general" validate:"omitempty,numeric" filter:"equal"`
+	Label            string `json:"label" loc:"language" filter:"like"`
+	LabelShort       string `json:"label_short" loc:"language" filter:"like"`
 }
 
 // List -
@@ -38,11 +37,8 @@ func List(r *ListRequest, format map[string]interface{}) *libresponse.Default {
 	d, _ := db.Open("")
 	defer d.Close()
 
-	params := map[string]interface{}{
-		"id":          r.ID,
-		"label":       r.Label,
-		"label_short": r.LabelShort,
-	}
+	tz, _ := format["tz"].(string)
+	filters, params := libquery.Filters(r, tz)
 	orderParams := map[string]interface{}{
 		"field":     r.OrderByField,
 		"direction": r.OrderByDir,
@@ -50,26 +46,18 @@ func List(r *ListRequest, format map[string]interface{}) *libresponse.Default {
 		"limit":     r.ItemsPerPage,
 	}
 
-	list, totalItems, err := language.List(d, params, orderParams)
-	if err != nil {
+	format["show_relationship"] = r.ShowRelationship
+	p, err := language.Paginate(d, filters, params, orderParams, func(obj interface{}) interface{} {
+		return FormatOutput(obj.(*language.Language), format)
+	})
+	if ve, ok := err.(*libquery.ValidationError); ok {
+		res = ve.Response()
+	} else if err != nil {
 		res.Code = 500
 		res.Message = "general.error_internal"
 		res.Error = "general.error_list"
 	} else {
-		tmp := make([]interface{}, len(list))
-		format["show_relationship"] = r.ShowRelationship
-		for k, obj := range list {
-			tmp[k] = FormatOutput(&obj, format)
-		}
-		res.Success = true
-		res.Code = 200
-		res.Message = "general.success_list"
-		totalPages := math.Ceil(float64(totalItems) / float64(r.ItemsPerPage))
-		res.Data = map[string]interface{}{
-			"items":       tmp,
-			"total_items": totalItems,
-			"total_pages": totalPages,
-		}
+		res.SuccessList(p)
 	}
 	return res
 }
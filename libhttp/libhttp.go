@@ -1,67 +1,80 @@
 package libhttp
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"os"
-	"strings"
+	"strconv"
 
+	"github.com/helloferdie/stdgo/libmetrics"
+	"github.com/helloferdie/stdgo/librequestid"
 	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/logger"
 )
 
+var metricRequests = libmetrics.NewCounter("http_client_requests_total", "Total outgoing HTTP requests", "method", "status")
+
+// metricRequest - Record outgoing request by method and status, status error when request fail
+func metricRequest(method string, res *http.Response, err error) {
+	status := "error"
+	if err == nil && res != nil {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	metricRequests.Inc(method, status)
+}
+
 // Request - request HTTP and expect response in JSON map[string]interface{}
 func Request(addr string, method string, payloadData map[string]interface{}, headerData map[string]string) (map[string]interface{}, int, error) {
-	payloadBytes, _ := json.Marshal(payloadData)
-	payload := strings.NewReader(string(payloadBytes))
-	req, err := http.NewRequest(method, addr, payload)
-	req.Header.Add("Content-Type", "application/json")
-	for k, v := range headerData {
-		req.Header.Add(k, v)
-	}
-	if err != nil {
-		logger.MakeLogEntry(nil, false).Error(err)
-		return nil, 0, err
-	}
+	return request(addr, method, payloadData, headerData, nil)
+}
 
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		logger.MakeLogEntry(nil, false).Error(err)
-		return nil, 0, err
-	}
-	defer res.Body.Close()
+// RequestSigned - request HTTP signed by signer and expect response in JSON map[string]interface{}
+func RequestSigned(signer *Signer, addr string, method string, payloadData map[string]interface{}, headerData map[string]string) (map[string]interface{}, int, error) {
+	return request(addr, method, payloadData, headerData, signer)
+}
 
-	resBody, err := ioutil.ReadAll(res.Body)
+// request -
+func request(addr string, method string, payloadData map[string]interface{}, headerData map[string]string, signer *Signer) (map[string]interface{}, int, error) {
+	resBody, statusCode, err := requestRaw(addr, method, payloadData, headerData, signer)
 	if err != nil {
-		logger.MakeLogEntry(nil, false).Error(err)
-		return nil, res.StatusCode, err
+		return nil, statusCode, err
 	}
 
 	var resJSON map[string]interface{}
-	err = json.Unmarshal(resBody, &resJSON)
+	err = json.Unmarshal([]byte(resBody), &resJSON)
 	if err != nil {
 		logger.MakeLogEntry(nil, false).Error(err)
-		return nil, res.StatusCode, err
+		return nil, statusCode, err
 	}
-	return resJSON, res.StatusCode, nil
+	return resJSON, statusCode, nil
 }
 
 // RequestRaw - request HTTP and expect response in raw string
 func RequestRaw(addr string, method string, payloadData map[string]interface{}, headerData map[string]string) (string, int, error) {
+	return requestRaw(addr, method, payloadData, headerData, nil)
+}
+
+// requestRaw -
+func requestRaw(addr string, method string, payloadData map[string]interface{}, headerData map[string]string, signer *Signer) (string, int, error) {
 	payloadBytes, _ := json.Marshal(payloadData)
-	payload := strings.NewReader(string(payloadBytes))
-	req, err := http.NewRequest(method, addr, payload)
+	req, err := http.NewRequest(method, addr, bytes.NewReader(payloadBytes))
+	if err != nil {
+		logger.MakeLogEntry(nil, false).Error(err)
+		return "", 0, err
+	}
 	req.Header.Add("Content-Type", "application/json")
 	for k, v := range headerData {
 		req.Header.Add(k, v)
 	}
-	if err != nil {
-		logger.MakeLogEntry(nil, false).Error(err)
-		return "", 0, err
+	if signer != nil {
+		signer.Sign(req, payloadBytes)
 	}
 
 	res, err := http.DefaultClient.Do(req)
+	metricRequest(method, res, err)
 	if err != nil {
 		logger.MakeLogEntry(nil, false).Error(err)
 		return "", 0, err
@@ -80,6 +93,7 @@ func RequestRaw(addr string, method string, payloadData map[string]interface{},
 // RequestMicroservice -
 func RequestMicroservice(f map[string]interface{}, addr string, method string, payloadData map[string]interface{}) (*libresponse.Default, int, error) {
 	headerData := map[string]string{
+		// Kept beside signature until every receiver verify signature
 		"X-Secret": os.Getenv("microservice_secret"),
 		"X-Locale": "0",
 	}
@@ -87,6 +101,9 @@ func RequestMicroservice(f map[string]interface{}, addr string, method string, p
 		if castedObject, ok := f["header"].(*libresponse.Header); ok {
 			headerData["Accept-Language"] = castedObject.AcceptLanguage
 			headerData["Accept-TZ"] = castedObject.AcceptTimezone
+			if castedObject.RequestID != "" {
+				headerData[librequestid.Header] = castedObject.RequestID
+			}
 			if castedObject.Authorization != "" {
 				headerData["Authorization"] = castedObject.Authorization
 			}
@@ -97,7 +114,7 @@ func RequestMicroservice(f map[string]interface{}, addr string, method string, p
 	if os.Getenv("audit_trail_gateway") == "1" {
 		auditTrailURL = os.Getenv("gateway_url") + addr
 	}
-	res, resCode, err := Request(auditTrailURL, method, payloadData, headerData)
+	res, resCode, err := RequestSigned(SignerFromEnv(), auditTrailURL, method, payloadData, headerData)
 
 	def := new(libresponse.Default)
 	jsonString, _ := json.Marshal(res)
@@ -110,8 +127,19 @@ func RequestMicroservice(f map[string]interface{}, addr string, method string, p
 	return def, resCode, err
 }
 
-// RequestAuditTrails -
+// RequestAuditTrails - Request audit trail without request ID, use RequestAuditTrailsContext in echo handler
 func RequestAuditTrails(payloadData map[string]interface{}) (*libresponse.Default, int, error) {
-	res, resCode, err := RequestMicroservice(nil, os.Getenv("audit_trail_url_create"), "POST", payloadData)
+	return RequestAuditTrailsContext(context.Background(), payloadData)
+}
+
+// RequestAuditTrailsContext - Request audit trail with request ID of ctx
+func RequestAuditTrailsContext(ctx context.Context, payloadData map[string]interface{}) (*libresponse.Default, int, error) {
+	var f map[string]interface{}
+	if id := librequestid.FromContext(ctx); id != "" {
+		f = map[string]interface{}{
+			"header": &libresponse.Header{RequestID: id},
+		}
+	}
+	res, resCode, err := RequestMicroservice(f, os.Getenv("audit_trail_url_create"), "POST", payloadData)
 	return res, resCode, err
 }
@@ -0,0 +1,42 @@
+package libidempotency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Record - Idempotency key record, Status is 0 while request is in progress
+type Record struct {
+	Key         string
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+	Unavailable bool // Request completed but response is not saved, retry can not be replayed
+	ExpiresAt   time.Time
+}
+
+// Completed - Whether response of record is saved
+func (r *Record) Completed() bool {
+	return r.Status > 0
+}
+
+// Store - Idempotency record storage, implement to share records between instances
+type Store interface {
+	// Lock - Save pending record of new or expired key for ttl and return nil, otherwise return existing record
+	Lock(key string, fingerprint string, ttl time.Duration) (*Record, error)
+	// Save - Save final response of key until rec.ExpiresAt
+	Save(rec *Record) error
+	// Delete - Remove record of key, e.g. to allow retry after failed request
+	Delete(key string) error
+}
+
+// Fingerprint - Hash of request method, URI and body
+func Fingerprint(method string, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + "\n" + uri + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,69 @@
+package libidempotency
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore - In-memory store of single instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*Record
+	lastPurge time.Time
+}
+
+// NewMemoryStore - Create in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records:   map[string]*Record{},
+		lastPurge: time.Now(),
+	}
+}
+
+// Lock - Lock key
+func (m *MemoryStore) Lock(key string, fingerprint string, ttl time.Duration) (*Record, error) {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastPurge) >= time.Minute {
+		m.purge(now)
+	}
+	if rec, exist := m.records[key]; exist && now.Before(rec.ExpiresAt) {
+		tmp := *rec
+		return &tmp, nil
+	}
+	m.records[key] = &Record{
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(ttl),
+	}
+	return nil, nil
+}
+
+// Save - Save response of key
+func (m *MemoryStore) Save(rec *Record) error {
+	tmp := *rec
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[rec.Key] = &tmp
+	return nil
+}
+
+// Delete - Delete key
+func (m *MemoryStore) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, key)
+	return nil
+}
+
+// purge - Remove expired record
+func (m *MemoryStore) purge(now time.Time) {
+	for key, rec := range m.records {
+		if !now.Before(rec.ExpiresAt) {
+			delete(m.records, key)
+		}
+	}
+	m.lastPurge = now
+}
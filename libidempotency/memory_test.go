@@ -0,0 +1,35 @@
+package libidempotency
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	m := NewMemoryStore()
+	if rec, _ := m.Lock("a", "f", time.Minute); rec != nil {
+		t.Fatalf("first lock = %+v, want nil", rec)
+	}
+	rec, _ := m.Lock("a", "f", time.Minute)
+	if rec == nil || rec.Completed() {
+		t.Fatalf("second lock = %+v, want pending record", rec)
+	}
+
+	m.Save(&Record{Key: "a", Fingerprint: "f", Status: 201, Body: []byte("ok"), ExpiresAt: time.Now().Add(time.Minute)})
+	rec, _ = m.Lock("a", "f", time.Minute)
+	if rec == nil || rec.Status != 201 || string(rec.Body) != "ok" {
+		t.Fatalf("lock of saved key = %+v, want saved record", rec)
+	}
+
+	m.Delete("a")
+	if rec, _ := m.Lock("a", "f", time.Minute); rec != nil {
+		t.Fatalf("lock of deleted key = %+v, want nil", rec)
+	}
+
+	if rec, _ := m.Lock("b", "f", -time.Second); rec != nil {
+		t.Fatalf("first lock = %+v, want nil", rec)
+	}
+	if rec, _ := m.Lock("b", "f", time.Minute); rec != nil {
+		t.Fatalf("lock of expired key = %+v, want nil", rec)
+	}
+}
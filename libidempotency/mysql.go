@@ -0,0 +1,146 @@
+package libidempotency
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/helloferdie/stdgo/db"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MySQLTable - Table definition of MySQL store
+const MySQLTable = `CREATE TABLE IF NOT EXISTS idempotency_keys (
+	idempotency_key VARCHAR(255) NOT NULL,
+	fingerprint CHAR(64) NOT NULL,
+	status_code INT NOT NULL DEFAULT 0,
+	header TEXT NOT NULL,
+	body MEDIUMBLOB NOT NULL,
+	unavailable TINYINT(1) NOT NULL DEFAULT 0,
+	expired_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL,
+	PRIMARY KEY (idempotency_key),
+	KEY idx_expired_at (expired_at)
+)`
+
+// MySQLStore - MySQL store shared between instances, see MySQLTable
+type MySQLStore struct {
+	DB    *sqlx.DB
+	Table string // Default idempotency_keys
+}
+
+// mysqlRecord -
+type mysqlRecord struct {
+	Key         string    `db:"idempotency_key"`
+	Fingerprint string    `db:"fingerprint"`
+	Status      int       `db:"status_code"`
+	Header      string    `db:"header"`
+	Body        []byte    `db:"body"`
+	Unavailable bool      `db:"unavailable"`
+	ExpiredAt   time.Time `db:"expired_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// NewMySQLStore - Create MySQL store
+func NewMySQLStore(d *sqlx.DB) *MySQLStore {
+	return &MySQLStore{DB: d, Table: "idempotency_keys"}
+}
+
+// table -
+func (m *MySQLStore) table() string {
+	if m.Table == "" {
+		return "idempotency_keys"
+	}
+	return m.Table
+}
+
+// Lock - Lock key, expired record is replaced
+func (m *MySQLStore) Lock(key string, fingerprint string, ttl time.Duration) (*Record, error) {
+	for retry := 0; retry < 3; retry++ {
+		now := time.Now().UTC()
+		_, _, err := db.Exec(m.DB, "DELETE FROM "+m.table()+" WHERE idempotency_key = :idempotency_key AND expired_at <= :now", map[string]interface{}{
+			"idempotency_key": key,
+			"now":             now,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, rows, err := db.Exec(m.DB, "INSERT IGNORE INTO "+m.table()+" (idempotency_key, fingerprint, status_code, header, body, expired_at, created_at) "+
+			"VALUES (:idempotency_key, :fingerprint, 0, '', '', :expired_at, :created_at)", map[string]interface{}{
+			"idempotency_key": key,
+			"fingerprint":     fingerprint,
+			"expired_at":      now.Add(ttl),
+			"created_at":      now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if rows > 0 {
+			return nil, nil
+		}
+
+		row := new(mysqlRecord)
+		exist, err := db.Get(m.DB, row, "SELECT * FROM "+m.table()+" WHERE idempotency_key = :idempotency_key", map[string]interface{}{
+			"idempotency_key": key,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			rec := &Record{
+				Key:         row.Key,
+				Fingerprint: row.Fingerprint,
+				Status:      row.Status,
+				Body:        row.Body,
+				Unavailable: row.Unavailable,
+				ExpiresAt:   row.ExpiredAt,
+			}
+			if row.Header != "" {
+				json.Unmarshal([]byte(row.Header), &rec.Header)
+			}
+			return rec, nil
+		}
+		// Record removed after insert, try again
+	}
+	return nil, fmt.Errorf("%s", "Fail to lock idempotency key")
+}
+
+// Save - Save response of key
+func (m *MySQLStore) Save(rec *Record) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+	body := rec.Body
+	if body == nil {
+		body = []byte{}
+	}
+	_, _, err = db.Exec(m.DB, "UPDATE "+m.table()+" SET status_code = :status_code, header = :header, body = :body, unavailable = :unavailable, expired_at = :expired_at "+
+		"WHERE idempotency_key = :idempotency_key", map[string]interface{}{
+		"idempotency_key": rec.Key,
+		"status_code":     rec.Status,
+		"header":          string(header),
+		"body":            body,
+		"unavailable":     rec.Unavailable,
+		"expired_at":      rec.ExpiresAt.UTC(),
+	})
+	return err
+}
+
+// Delete - Delete key
+func (m *MySQLStore) Delete(key string) error {
+	_, _, err := db.Exec(m.DB, "DELETE FROM "+m.table()+" WHERE idempotency_key = :idempotency_key", map[string]interface{}{
+		"idempotency_key": key,
+	})
+	return err
+}
+
+// Purge - Remove expired record, run periodically to keep table small
+func (m *MySQLStore) Purge() (int64, error) {
+	_, rows, err := db.Exec(m.DB, "DELETE FROM "+m.table()+" WHERE expired_at <= :now", map[string]interface{}{
+		"now": time.Now().UTC(),
+	})
+	return rows, err
+}
@@ -0,0 +1,87 @@
+package libidempotency
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/db"
+)
+
+// newMySQLStore - Store of temporary table in database of db_host env, test is skipped without database
+func newMySQLStore(t *testing.T) *MySQLStore {
+	if os.Getenv("db_host") == "" {
+		t.Skip("db_host not set, MySQL store needs a database")
+	}
+	d, err := db.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := "idempotency_keys_test_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	if _, err := d.Exec(strings.Replace(MySQLTable, "idempotency_keys", table, 1)); err != nil {
+		d.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		d.Exec("DROP TABLE " + table)
+		d.Close()
+	})
+	return &MySQLStore{DB: d, Table: table}
+}
+
+func TestMySQLStore(t *testing.T) {
+	m := newMySQLStore(t)
+
+	if rec, err := m.Lock("a", "f", time.Minute); err != nil || rec != nil {
+		t.Fatalf("first lock = %+v, %v, want nil", rec, err)
+	}
+	rec, err := m.Lock("a", "f", time.Minute)
+	if err != nil || rec == nil || rec.Completed() {
+		t.Fatalf("second lock = %+v, %v, want pending record", rec, err)
+	}
+
+	saved := &Record{Key: "a", Fingerprint: "f", Status: 201, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte("ok"), ExpiresAt: time.Now().Add(time.Minute)}
+	if err := m.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	rec, err = m.Lock("a", "f", time.Minute)
+	if err != nil || rec == nil || rec.Status != 201 || string(rec.Body) != "ok" || rec.Header.Get("Content-Type") != "application/json" {
+		t.Fatalf("lock of saved key = %+v, %v, want saved record", rec, err)
+	}
+
+	saved.Header, saved.Body, saved.Unavailable = nil, nil, true
+	if err := m.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	if rec, _ = m.Lock("a", "f", time.Minute); rec == nil || !rec.Unavailable || !rec.Completed() {
+		t.Fatalf("lock of unavailable key = %+v, want completed unavailable record", rec)
+	}
+
+	if err := m.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if rec, err := m.Lock("a", "f", time.Minute); err != nil || rec != nil {
+		t.Fatalf("lock of deleted key = %+v, %v, want nil", rec, err)
+	}
+
+	// Expired row is replaced by DELETE then INSERT IGNORE
+	if rec, _ := m.Lock("b", "f", -time.Second); rec != nil {
+		t.Fatalf("first lock = %+v, want nil", rec)
+	}
+	if rec, err := m.Lock("b", "g", time.Minute); err != nil || rec != nil {
+		t.Fatalf("lock of expired key = %+v, %v, want nil", rec, err)
+	}
+	if rec, _ := m.Lock("b", "g", time.Minute); rec == nil || rec.Fingerprint != "g" {
+		t.Fatalf("lock of replaced key = %+v, want record of new fingerprint", rec)
+	}
+
+	if rec, _ := m.Lock("c", "f", -time.Second); rec != nil {
+		t.Fatalf("first lock = %+v, want nil", rec)
+	}
+	if n, err := m.Purge(); err != nil || n != 1 {
+		t.Errorf("Purge() = %d, %v, want 1", n, err)
+	}
+}
@@ -0,0 +1,229 @@
+package liblocale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/helloferdie/stdgo/logger"
+)
+
+// Catalog - Locale messages of every <lang>/<namespace>.json file kept in memory
+type Catalog struct {
+	fs http.FileSystem
+
+	mu        sync.RWMutex
+	messages  map[string]map[string]map[string]string // lang > namespace > key > message
+	signature string
+}
+
+// NewCatalog - Create catalog of directory
+func NewCatalog(dir string) (*Catalog, error) {
+	return NewCatalogFS(http.Dir(dir))
+}
+
+// NewCatalogFS - Create catalog of file system, use http.FS to load from embed.FS
+func NewCatalogFS(fs http.FileSystem) (*Catalog, error) {
+	c := &Catalog{
+		fs:       fs,
+		messages: map[string]map[string]map[string]string{},
+	}
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reload - Load every file and swap messages, keep current messages when directory fail to read,
+// file fail to load is logged and keep its current messages
+func (c *Catalog) Reload() error {
+	messages := map[string]map[string]map[string]string{}
+	files, signature, err := c.scan()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		lang, ns := path.Base(path.Dir(f)), strings.TrimSuffix(path.Base(f), ".json")
+		m, err := c.readFile(f)
+		if err != nil {
+			logger.MakeLogEntry(nil, false).Errorf("Fail to load locale %s: %v", f, err)
+			c.mu.RLock()
+			m = c.messages[lang][ns]
+			c.mu.RUnlock()
+			if m == nil {
+				continue
+			}
+		}
+		if messages[lang] == nil {
+			messages[lang] = map[string]map[string]string{}
+		}
+		messages[lang][ns] = m
+	}
+
+	c.mu.Lock()
+	c.messages = messages
+	c.signature = signature
+	c.mu.Unlock()
+	return nil
+}
+
+// Watch - Reload catalog when any file change, checked every interval until ctx done
+func (c *Catalog) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, signature, err := c.scan()
+			if err != nil {
+				logger.MakeLogEntry(nil, false).Errorf("Fail to check locale %v", err)
+				continue
+			}
+			c.mu.RLock()
+			changed := signature != c.signature
+			c.mu.RUnlock()
+			if !changed {
+				continue
+			}
+			if err := c.Reload(); err != nil {
+				logger.MakeLogEntry(nil, false).Errorf("Fail to reload locale %v", err)
+				continue
+			}
+			logger.MakeLogEntry(nil, false).Info("Reload locale catalog")
+		}
+	}
+}
+
+// Lookup - Return message of key in namespace of language
+func (c *Catalog) Lookup(lang string, namespace string, key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	msg, exist := c.messages[lang][namespace][key]
+	return msg, exist
+}
+
+// HasNamespace - Check language has namespace file
+func (c *Catalog) HasNamespace(lang string, namespace string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, exist := c.messages[lang][namespace]
+	return exist
+}
+
+// Languages - Return sorted language of catalog
+func (c *Catalog) Languages() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list := make([]string, 0, len(c.messages))
+	for lang := range c.messages {
+		list = append(list, lang)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Namespaces - Return sorted namespace of language
+func (c *Catalog) Namespaces(lang string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	list := make([]string, 0, len(c.messages[lang]))
+	for ns := range c.messages[lang] {
+		list = append(list, ns)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// Messages - Return copy of every message of namespace in language
+func (c *Catalog) Messages(lang string, namespace string) map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := map[string]string{}
+	for k, v := range c.messages[lang][namespace] {
+		m[k] = v
+	}
+	return m
+}
+
+// scan - Return every <lang>/<namespace>.json file and signature of name, size and modification time
+func (c *Catalog) scan() ([]string, string, error) {
+	langs, err := c.readDir("/")
+	if err != nil {
+		return nil, "", err
+	}
+	files := []string{}
+	var sig strings.Builder
+	for _, l := range langs {
+		if !l.IsDir() {
+			continue
+		}
+		list, err := c.readDir("/" + l.Name())
+		if err != nil {
+			return nil, "", err
+		}
+		for _, f := range list {
+			if f.IsDir() || !strings.HasSuffix(f.Name(), ".json") {
+				continue
+			}
+			name := "/" + l.Name() + "/" + f.Name()
+			files = append(files, name)
+			fmt.Fprintf(&sig, "%s:%d:%d;", name, f.Size(), f.ModTime().UnixNano())
+		}
+	}
+	return files, sig.String(), nil
+}
+
+// readDir - Return sorted entry of directory
+func (c *Catalog) readDir(name string) ([]os.FileInfo, error) {
+	d, err := c.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+	list, err := d.Readdir(-1)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name() < list[j].Name()
+	})
+	return list, nil
+}
+
+// readFile - Read string messages of file, non string value is skipped
+func (c *Catalog) readFile(name string) (map[string]string, error) {
+	f, err := c.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	m := map[string]string{}
+	for k, v := range raw {
+		if s, ok := v.(string); ok {
+			m[k] = s
+		}
+	}
+	return m, nil
+}
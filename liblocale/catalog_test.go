@@ -0,0 +1,64 @@
+package liblocale
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile - Write locale file of test
+func writeFile(t *testing.T, dir string, lang string, ns string, content string) {
+	if err := os.MkdirAll(filepath.Join(dir, lang), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, lang, ns+".json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCatalogReloadBadFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "locale")
+	defer os.RemoveAll(dir)
+	writeFile(t, dir, "en", "general", `{"success": "ok"}`)
+	writeFile(t, dir, "en", "audit", `{"broken"`)
+
+	c, err := NewCatalog(dir)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	if msg, _ := c.Lookup("en", "general", "success"); msg != "ok" {
+		t.Errorf("general.success = %q, want ok", msg)
+	}
+	if c.HasNamespace("en", "audit") {
+		t.Error("broken namespace loaded")
+	}
+
+	// Broken file on reload keep its current messages
+	writeFile(t, dir, "en", "audit", `{"title": "Audit"}`)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "en", "audit", `{"title"`)
+	writeFile(t, dir, "en", "general", `{"success": "done"}`)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ns, key, want string
+	}{
+		{"audit", "title", "Audit"},
+		{"general", "success", "done"},
+	}
+	for _, tt := range tests {
+		if msg, _ := c.Lookup("en", tt.ns, tt.key); msg != tt.want {
+			t.Errorf("%s.%s = %q, want %q", tt.ns, tt.key, msg, tt.want)
+		}
+	}
+}
+
+func TestNewCatalogMissingDir(t *testing.T) {
+	if _, err := NewCatalog(filepath.Join(os.TempDir(), "locale-missing-dir")); err == nil {
+		t.Error("NewCatalog() of missing directory succeeded")
+	}
+}
@@ -0,0 +1,228 @@
+package liblocale
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Issue kind
+const (
+	IssueMissing      = "missing"      // Key of default locale missing in language
+	IssueExtra        = "extra"        // Key not in default locale
+	IssuePlaceholder  = "placeholder"  // Placeholder differ from default locale
+	IssueUntranslated = "untranslated" // Key used in source without translation in default locale
+)
+
+// Issue - Locale problem
+type Issue struct {
+	Kind   string `json:"kind"`
+	Lang   string `json:"lang,omitempty"`
+	Key    string `json:"key"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// String -
+func (i Issue) String() string {
+	s := i.Kind + " " + i.Key
+	if i.Lang != "" {
+		s = i.Kind + " [" + i.Lang + "] " + i.Key
+	}
+	if i.Detail != "" {
+		s += ": " + i.Detail
+	}
+	return s
+}
+
+// SourceKey - Locale key used in source
+type SourceKey struct {
+	Key      string
+	Position string
+}
+
+// Check - Compare every language with default language, report missing and extra key and placeholder mismatch
+func Check(c *Catalog, defaultLang string) []Issue {
+	issues := []Issue{}
+	base := map[string]string{}
+	for _, ns := range c.Namespaces(defaultLang) {
+		for k, v := range c.Messages(defaultLang, ns) {
+			base[ns+"."+k] = v
+		}
+	}
+
+	for _, lang := range c.Languages() {
+		if lang == defaultLang {
+			continue
+		}
+		msgs := map[string]string{}
+		for _, ns := range c.Namespaces(lang) {
+			for k, v := range c.Messages(lang, ns) {
+				msgs[ns+"."+k] = v
+			}
+		}
+		for _, key := range sortedKeys(base) {
+			v, exist := msgs[key]
+			if !exist {
+				issues = append(issues, Issue{Kind: IssueMissing, Lang: lang, Key: key})
+				continue
+			}
+			if want, got := Placeholders(base[key]), Placeholders(v); want != got {
+				issues = append(issues, Issue{Kind: IssuePlaceholder, Lang: lang, Key: key, Detail: fmt.Sprintf("%s expect %s", got, want)})
+			}
+		}
+		for _, key := range sortedKeys(msgs) {
+			if _, exist := base[key]; !exist {
+				issues = append(issues, Issue{Kind: IssueExtra, Lang: lang, Key: key})
+			}
+		}
+	}
+	return issues
+}
+
+// CheckSource - Report key used in source without translation in default language
+func CheckSource(c *Catalog, defaultLang string, keys []SourceKey) []Issue {
+	issues := []Issue{}
+	seen := map[string]bool{}
+	for _, sk := range keys {
+		split := strings.SplitN(sk.Key, ".", 2)
+		if len(split) < 2 || seen[sk.Key] {
+			continue
+		}
+		if _, exist := c.Lookup(defaultLang, split[0], split[1]); !exist {
+			seen[sk.Key] = true
+			issues = append(issues, Issue{Kind: IssueUntranslated, Lang: defaultLang, Key: sk.Key, Detail: sk.Position})
+		}
+	}
+	return issues
+}
+
+// Regex for locale key literal, e.g. general.error_not_found
+var regexSourceKey = regexp.MustCompile(`^[a-z][a-z0-9_]*\.(error|success)[a-z0-9_]*$`)
+
+// Regex for escaped percent or fmt verb, escaped percent is matched first so "%%d" is not counted as verb
+var regexVerbCount = regexp.MustCompile(`%%|` + regexVerb.String())
+
+// ScanSource - Find locale key literal and loc / validate tagged field label (<loc>.var_<json>) in Go file under root
+func ScanSource(root string) ([]SourceKey, error) {
+	keys := []SourceKey{}
+	fset := token.NewFileSet()
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch t := n.(type) {
+			case *ast.BasicLit:
+				if t.Kind != token.STRING {
+					return true
+				}
+				if s, err := strconv.Unquote(t.Value); err == nil && regexSourceKey.MatchString(s) {
+					keys = append(keys, SourceKey{Key: s, Position: fset.Position(t.Pos()).String()})
+				}
+			case *ast.Field:
+				if key := fieldLabel(t); key != "" {
+					keys = append(keys, SourceKey{Key: key, Position: fset.Position(t.Pos()).String()})
+				}
+				// Tag literal is not a locale key
+				return false
+			}
+			return true
+		})
+		return nil
+	})
+	return keys, err
+}
+
+// fieldLabel - Return label key of validated struct field, same as libvalidator
+func fieldLabel(f *ast.Field) string {
+	if f.Tag == nil {
+		return ""
+	}
+	raw, err := strconv.Unquote(f.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	tag := reflect.StructTag(raw)
+	loc, hasLoc := tag.Lookup("loc")
+	_, hasValidate := tag.Lookup("validate")
+	if !hasLoc && !hasValidate {
+		return ""
+	}
+	name := strings.SplitN(tag.Get("json"), ",", 2)[0]
+	if name == "" || name == "-" {
+		return ""
+	}
+	if loc == "" {
+		loc = "general"
+	}
+	return loc + ".var_" + name
+}
+
+// Placeholders - Return placeholder signature of message, fmt verb count or sorted message format argument
+func Placeholders(msg string) string {
+	if hasBrace(msg) {
+		// Message kept as plain text at runtime when it fail to parse, still reported to fix the translation
+		nodes, err := parse(msg)
+		if err != nil {
+			return "invalid message format"
+		}
+		set := map[string]bool{}
+		collectArgs(nodes, set)
+		return "{" + strings.Join(sortedKeys(set), ", ") + "}"
+	}
+	n := 0
+	for _, m := range regexVerbCount.FindAllString(msg, -1) {
+		if m != "%%" {
+			n++
+		}
+	}
+	return strconv.Itoa(n) + " placeholder"
+}
+
+// collectArgs -
+func collectArgs(nodes []msgNode, set map[string]bool) {
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case *argNode:
+			set[t.name] = true
+		case *choiceNode:
+			set[t.name] = true
+			for _, sub := range t.options {
+				collectArgs(sub, set)
+			}
+		}
+	}
+}
+
+// sortedKeys -
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,25 @@
+package liblocale
+
+import "testing"
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"Hello", "0 placeholder"},
+		{"Hello %s, you have %d message", "2 placeholder"},
+		{"Discount 10%% for %s", "1 placeholder"},
+		{"Literal %%d is not verb", "0 placeholder"},
+		{"Width %-5.2f", "1 placeholder"},
+		{"{count, plural, one {# item} other {# items}} of {name}", "{count, name}"},
+		{"{count, plural, one {# item}", "invalid message format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := Placeholders(tt.msg); got != tt.want {
+				t.Errorf("Placeholders(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package liblocale
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// number - Numeric argument with visible decimals
+type number struct {
+	value    float64
+	decimals int
+}
+
+// numberSymbol - Group and decimal separator
+type numberSymbol struct {
+	group   string
+	decimal string
+}
+
+// numberSymbols - Separator per language, default en
+var numberSymbols = map[string]numberSymbol{
+	"en": {",", "."},
+	"id": {".", ","},
+	"ms": {",", "."},
+	"de": {".", ","},
+	"nl": {".", ","},
+	"es": {".", ","},
+	"it": {".", ","},
+	"pt": {".", ","},
+	"tr": {".", ","},
+	"fr": {" ", ","},
+	"ru": {" ", ","},
+	"uk": {" ", ","},
+	"pl": {" ", ","},
+	"cs": {" ", ","},
+	"sv": {" ", ","},
+}
+
+// FormatNumber - Format number with group and decimal separator of language
+func FormatNumber(lang string, v float64, decimals int) string {
+	sym, exist := numberSymbols[baseLanguage(lang)]
+	if !exist {
+		sym = numberSymbols["en"]
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(sym.group)
+		}
+		b.WriteRune(r)
+	}
+	if fracPart != "" {
+		b.WriteString(sym.decimal)
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// dateFormat - Go layout of date style
+type dateFormat struct {
+	short  string
+	medium string
+	long   string
+	full   string
+	months []string // Month name replacing English month name, empty to keep English
+	days   []string // Day name replacing English day name
+}
+
+// dateFormats - Date format per language, default en
+var dateFormats = map[string]dateFormat{
+	"en": {
+		short:  "1/2/06",
+		medium: "Jan 2, 2006",
+		long:   "January 2, 2006",
+		full:   "Monday, January 2, 2006",
+	},
+	"id": {
+		short:  "02/01/06",
+		medium: "2 Jan 2006",
+		long:   "2 January 2006",
+		full:   "Monday, 02 January 2006",
+		months: []string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		days:   []string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"},
+	},
+	"de": {
+		short:  "02.01.06",
+		medium: "02.01.2006",
+		long:   "2. January 2006",
+		full:   "Monday, 2. January 2006",
+		months: []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		days:   []string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+	},
+}
+
+// FormatDate - Format date in style short, medium (default), long or full of language, ISO date when language unknown
+func FormatDate(lang string, t time.Time, style string) string {
+	f, exist := dateFormats[baseLanguage(lang)]
+	if !exist {
+		return t.Format("2006-01-02")
+	}
+
+	layout := f.medium
+	switch style {
+	case "short":
+		layout = f.short
+	case "long":
+		layout = f.long
+	case "full":
+		layout = f.full
+	}
+	s := t.Format(layout)
+	if len(f.months) == 12 {
+		month := t.Month().String()
+		if strings.Contains(layout, "January") {
+			s = strings.Replace(s, month, f.months[t.Month()-1], 1)
+		} else if strings.Contains(layout, "Jan") {
+			s = strings.Replace(s, month[:3], shortName(f.months[t.Month()-1]), 1)
+		}
+	}
+	if len(f.days) == 7 && strings.Contains(layout, "Monday") {
+		s = strings.Replace(s, t.Weekday().String(), f.days[t.Weekday()], 1)
+	}
+	return s
+}
+
+// FormatTime - Format time in style short (default) or medium
+func FormatTime(lang string, t time.Time, style string) string {
+	layout := "15:04"
+	if baseLanguage(lang) == "en" {
+		layout = "3:04 PM"
+	}
+	if style == "medium" || style == "long" || style == "full" {
+		layout = strings.Replace(layout, "04", "04:05", 1)
+	}
+	s := t.Format(layout)
+	if baseLanguage(lang) == "id" {
+		s = strings.Replace(s, ":", ".", -1)
+	}
+	return s
+}
+
+// shortName - First three letters of name
+func shortName(s string) string {
+	r := []rune(s)
+	if len(r) > 3 {
+		r = r[:3]
+	}
+	return string(r)
+}
+
+// toNumber - Convert argument to number
+func toNumber(v interface{}) (*number, bool) {
+	switch t := v.(type) {
+	case int:
+		return &number{value: float64(t)}, true
+	case int8:
+		return &number{value: float64(t)}, true
+	case int16:
+		return &number{value: float64(t)}, true
+	case int32:
+		return &number{value: float64(t)}, true
+	case int64:
+		return &number{value: float64(t)}, true
+	case uint:
+		return &number{value: float64(t)}, true
+	case uint8:
+		return &number{value: float64(t)}, true
+	case uint16:
+		return &number{value: float64(t)}, true
+	case uint32:
+		return &number{value: float64(t)}, true
+	case uint64:
+		return &number{value: float64(t)}, true
+	case float32:
+		return floatNumber(strconv.FormatFloat(float64(t), 'f', -1, 32))
+	case float64:
+		return floatNumber(strconv.FormatFloat(t, 'f', -1, 64))
+	case json.Number:
+		return floatNumber(t.String())
+	case string:
+		return floatNumber(strings.TrimSpace(t))
+	}
+	return nil, false
+}
+
+// floatNumber - Parse number keeping visible decimals
+func floatNumber(s string) (*number, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+	n := &number{value: f}
+	if i := strings.IndexByte(s, '.'); i >= 0 && !strings.ContainsAny(s, "eE") {
+		n.decimals = len(s) - i - 1
+	}
+	return n, true
+}
+
+// toTime - Convert time, RFC 3339 or "2006-01-02 15:04:05" string and unix second to time
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t != nil {
+			return *t, true
+		}
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02"} {
+			if tm, err := time.Parse(layout, t); err == nil {
+				return tm, true
+			}
+		}
+	default:
+		if n, ok := toNumber(v); ok {
+			return time.Unix(int64(n.value), 0).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,350 @@
+package liblocale
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// msgNode - Parsed part of message pattern
+type msgNode interface{}
+
+// textNode - Literal text
+type textNode string
+
+// hashNode - # inside plural, replaced by number minus offset
+type hashNode struct{}
+
+// argNode - {name}, {name, number[, style]} or {name, date|time[, style]}
+type argNode struct {
+	name  string
+	typ   string
+	style string
+}
+
+// choiceNode - {name, plural|selectordinal|select, [offset:n] key {message} ...}
+type choiceNode struct {
+	name    string
+	typ     string
+	offset  float64
+	options map[string][]msgNode
+}
+
+// msgParser -
+type msgParser struct {
+	src []rune
+	pos int
+}
+
+// Regex for fmt verb of positional template
+var regexVerb = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[vTtbcdoOqxXUeEfFgGsp]`)
+
+// IsMessageFormat - Check message use message format placeholder instead of fmt verb,
+// message with brace that fail to parse is plain text kept as is, e.g. "Body {"id": 1} not valid"
+func IsMessageFormat(msg string) bool {
+	if !hasBrace(msg) {
+		return false
+	}
+	_, err := parse(msg)
+	return err == nil
+}
+
+// hasBrace - Check message has brace and no fmt verb, candidate of message format
+func hasBrace(msg string) bool {
+	return strings.Contains(msg, "{") && strings.Contains(msg, "}") && !regexVerb.MatchString(msg)
+}
+
+// parse - Parse whole message pattern
+func parse(pattern string) ([]msgNode, error) {
+	p := &msgParser{src: []rune(pattern)}
+	nodes, err := p.parseMessage(false, false, 0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.src[p.pos], p.pos)
+	}
+	return nodes, nil
+}
+
+// Format - Format message pattern with named argument in language, e.g. "{count, plural, one {# file} other {# files}}"
+func Format(lang string, pattern string, args map[string]interface{}) (string, error) {
+	nodes, err := parse(pattern)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := render(&b, lang, nodes, args, nil); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// parseMessage - Parse until end or closing brace when nested, # is number when hash
+func (p *msgParser) parseMessage(nested bool, hash bool, depth int) ([]msgNode, error) {
+	if depth > 20 {
+		return nil, fmt.Errorf("%s", "message nested too deep")
+	}
+	nodes := []msgNode{}
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == '\'':
+			p.pos++
+			if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+				text.WriteRune('\'')
+				p.pos++
+			} else if p.pos < len(p.src) && (p.src[p.pos] == '{' || p.src[p.pos] == '}' || (hash && p.src[p.pos] == '#')) {
+				// Quoted literal until next single apostrophe
+				for p.pos < len(p.src) {
+					if p.src[p.pos] == '\'' {
+						if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+							text.WriteRune('\'')
+							p.pos += 2
+							continue
+						}
+						p.pos++
+						break
+					}
+					text.WriteRune(p.src[p.pos])
+					p.pos++
+				}
+			} else {
+				text.WriteRune('\'')
+			}
+		case r == '{':
+			flush()
+			p.pos++
+			n, err := p.parseArgument(hash, depth)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		case r == '}':
+			if !nested {
+				return nil, fmt.Errorf("unexpected } at %d", p.pos)
+			}
+			flush()
+			return nodes, nil
+		case r == '#' && hash:
+			flush()
+			nodes = append(nodes, hashNode{})
+			p.pos++
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+	if nested {
+		return nil, fmt.Errorf("%s", "missing closing }")
+	}
+	flush()
+	return nodes, nil
+}
+
+// parseArgument - Parse argument after opening brace up to and including closing brace, select inherit # of outer plural
+func (p *msgParser) parseArgument(hash bool, depth int) (msgNode, error) {
+	name := p.parseWord()
+	if name == "" {
+		return nil, fmt.Errorf("missing argument name at %d", p.pos)
+	}
+	p.skipSpace()
+	if p.consume('}') {
+		return &argNode{name: name}, nil
+	}
+	if !p.consume(',') {
+		return nil, fmt.Errorf("expected , or } at %d", p.pos)
+	}
+	p.skipSpace()
+	typ := p.parseWord()
+	p.skipSpace()
+
+	switch typ {
+	case "number", "date", "time":
+		style := ""
+		if p.consume(',') {
+			p.skipSpace()
+			style = p.parseWord()
+			p.skipSpace()
+		}
+		if !p.consume('}') {
+			return nil, fmt.Errorf("expected } at %d", p.pos)
+		}
+		return &argNode{name: name, typ: typ, style: style}, nil
+	case "plural", "selectordinal", "select":
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected , at %d", p.pos)
+		}
+		n := &choiceNode{name: name, typ: typ, options: map[string][]msgNode{}}
+		for {
+			p.skipSpace()
+			if p.consume('}') {
+				break
+			}
+			key := p.parseWord()
+			if key == "" {
+				return nil, fmt.Errorf("expected option at %d", p.pos)
+			}
+			if typ != "select" && strings.HasPrefix(key, "offset:") {
+				v, err := strconv.ParseFloat(strings.TrimPrefix(key, "offset:"), 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s", key)
+				}
+				n.offset = v
+				continue
+			}
+			p.skipSpace()
+			if !p.consume('{') {
+				return nil, fmt.Errorf("expected { of option %s at %d", key, p.pos)
+			}
+			sub, err := p.parseMessage(true, hash || typ != "select", depth+1)
+			if err != nil {
+				return nil, err
+			}
+			p.pos++ // Closing brace of option
+			n.options[key] = sub
+		}
+		if _, exist := n.options["other"]; !exist {
+			return nil, fmt.Errorf("%s of %s missing other option", typ, name)
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("unknown argument type %s", typ)
+}
+
+// parseWord - Parse name, type, style or option key
+func (p *msgParser) parseWord() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// skipSpace -
+func (p *msgParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// consume - Consume rune when next
+func (p *msgParser) consume(r rune) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// render - Write formatted nodes, hash is number of nearest plural
+func render(b *strings.Builder, lang string, nodes []msgNode, args map[string]interface{}, hash *number) error {
+	for _, n := range nodes {
+		switch t := n.(type) {
+		case textNode:
+			b.WriteString(string(t))
+		case hashNode:
+			if hash != nil {
+				b.WriteString(FormatNumber(lang, hash.value, hash.decimals))
+			} else {
+				b.WriteRune('#')
+			}
+		case *argNode:
+			v, exist := args[t.name]
+			if !exist {
+				return fmt.Errorf("missing argument %s", t.name)
+			}
+			s, err := formatArg(lang, t, v)
+			if err != nil {
+				return err
+			}
+			b.WriteString(s)
+		case *choiceNode:
+			v, exist := args[t.name]
+			if !exist {
+				return fmt.Errorf("missing argument %s", t.name)
+			}
+			if t.typ == "select" {
+				sub, ok := t.options[fmt.Sprint(v)]
+				if !ok {
+					sub = t.options["other"]
+				}
+				if err := render(b, lang, sub, args, hash); err != nil {
+					return err
+				}
+				continue
+			}
+
+			num, ok := toNumber(v)
+			if !ok {
+				return fmt.Errorf("argument %s is not a number", t.name)
+			}
+			sub, ok := t.options["="+strconv.FormatFloat(num.value, 'f', -1, 64)]
+			rel := &number{value: num.value - t.offset, decimals: num.decimals}
+			if !ok {
+				category := PluralCategory(lang, rel.value, rel.decimals)
+				if t.typ == "selectordinal" {
+					category = OrdinalCategory(lang, rel.value)
+				}
+				if sub, ok = t.options[category]; !ok {
+					sub = t.options["other"]
+				}
+			}
+			if err := render(b, lang, sub, args, rel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatArg - Format simple argument
+func formatArg(lang string, a *argNode, v interface{}) (string, error) {
+	switch a.typ {
+	case "number":
+		num, ok := toNumber(v)
+		if !ok {
+			return "", fmt.Errorf("argument %s is not a number", a.name)
+		}
+		switch a.style {
+		case "integer":
+			return FormatNumber(lang, num.value, 0), nil
+		case "percent":
+			return FormatNumber(lang, num.value*100, 0) + "%", nil
+		}
+		decimals := num.decimals
+		if decimals > 3 {
+			decimals = 3
+		}
+		return FormatNumber(lang, num.value, decimals), nil
+	case "date", "time":
+		t, ok := toTime(v)
+		if !ok {
+			return "", fmt.Errorf("argument %s is not a time", a.name)
+		}
+		if a.typ == "time" {
+			return FormatTime(lang, t, a.style), nil
+		}
+		return FormatDate(lang, t, a.style), nil
+	}
+	if num, ok := toNumber(v); ok {
+		if _, isString := v.(string); !isString {
+			return FormatNumber(lang, num.value, num.decimals), nil
+		}
+	}
+	return fmt.Sprint(v), nil
+}
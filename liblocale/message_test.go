@@ -0,0 +1,67 @@
+package liblocale
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	const files = "{n, plural, =0 {none} one {# file} other {# files}}"
+	tests := []struct {
+		name    string
+		lang    string
+		pattern string
+		args    map[string]interface{}
+		want    string
+		wantErr bool
+	}{
+		{"plural exact", "en", files, map[string]interface{}{"n": 0}, "none", false},
+		{"plural one", "en", files, map[string]interface{}{"n": 1}, "1 file", false},
+		{"plural other with grouping", "en", files, map[string]interface{}{"n": 1234}, "1,234 files", false},
+		{"plural language without one", "id", "{n, plural, one {# file} other {# files}}", map[string]interface{}{"n": 1}, "1 files", false},
+		{"plural french zero is one", "fr", "{n, plural, one {# fichier} other {# fichiers}}", map[string]interface{}{"n": 0}, "0 fichier", false},
+		{"plural offset", "en", "{n, plural, offset:1 =0 {nobody} one {you} other {you and # others}}", map[string]interface{}{"n": 3}, "you and 2 others", false},
+		{"selectordinal", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", map[string]interface{}{"n": 22}, "22nd", false},
+		{"select and plural", "en", "{g, select, male {He} female {She} other {They}} liked {n, plural, one {# post} other {# posts}}", map[string]interface{}{"g": "female", "n": 2}, "She liked 2 posts", false},
+		{"select other", "en", "{g, select, male {He} other {They}}", map[string]interface{}{"g": "x"}, "They", false},
+		{"plural nested in select", "en", "{g, select, male {{n, plural, one {# x} other {# xs}}} other {none}}", map[string]interface{}{"g": "male", "n": 5}, "5 xs", false},
+		{"quoted brace", "en", "'{'literal'}' {n}", map[string]interface{}{"n": "x"}, "{literal} x", false},
+		{"missing other", "en", "{n, plural, one {# file}}", map[string]interface{}{"n": 2}, "", true},
+		{"unclosed select", "en", "{g, select, male {He}", map[string]interface{}{"g": "x"}, "", true},
+		{"missing argument", "en", files, map[string]interface{}{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.lang, tt.pattern, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Format() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsMessageFormat(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"{n, plural, one {# file} other {# files}}", true},
+		{"Page {0} of {1}", true},
+		{"'{'literal'}' {n}", true},
+		{"Use {name} as placeholder", true},
+		{"Plain message", false},
+		{"Found %d {items}", false},
+		{"Found %v", false},
+		{`Body {"id": 1} not valid`, false},
+		{"{n, plural, one {# file}", false},
+		{"Closing } before {n}", false},
+		{"{}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := IsMessageFormat(tt.msg); got != tt.want {
+				t.Errorf("IsMessageFormat(%q) = %v, want %v", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
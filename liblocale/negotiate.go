@@ -0,0 +1,151 @@
+package liblocale
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LanguageQ - Language of Accept-Language header with quality
+type LanguageQ struct {
+	Tag string
+	Q   float64
+}
+
+// ParseAcceptLanguage - Parse Accept-Language header sorted by quality, q=0 and wildcard are excluded
+func ParseAcceptLanguage(header string) []LanguageQ {
+	list := []LanguageQ{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := NormalizeTag(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		valid := true
+		for _, f := range fields[1:] {
+			f = strings.TrimSpace(f)
+			if !strings.HasPrefix(f, "q=") {
+				continue
+			}
+			v, err := strconv.ParseFloat(f[2:], 64)
+			if err != nil || v < 0 || v > 1 {
+				valid = false
+				break
+			}
+			q = v
+		}
+		if valid && q > 0 {
+			list = append(list, LanguageQ{Tag: tag, Q: q})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Q > list[j].Q
+	})
+	return list
+}
+
+// NormalizeTag - Normalize BCP 47 tag case and separator, e.g. en_gb to en-GB
+func NormalizeTag(tag string) string {
+	parts := strings.Split(strings.Replace(strings.TrimSpace(tag), "_", "-", -1), "-")
+	for i, p := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(p)
+		case len(p) == 2:
+			parts[i] = strings.ToUpper(p) // Region
+		case len(p) == 4:
+			parts[i] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:]) // Script
+		default:
+			parts[i] = strings.ToLower(p)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// Negotiator - Pick available language of user preference
+type Negotiator struct {
+	Fallback map[string][]string // Fallback chain per language, e.g. "ms": {"id"}
+	Default  []string            // Last resort chain
+}
+
+// Negotiate - Return best available language of Accept-Language header or single tag, empty when nothing match
+func (n *Negotiator) Negotiate(header string, available []string) string {
+	tags := []string{}
+	for _, l := range ParseAcceptLanguage(header) {
+		tags = append(tags, l.Tag)
+	}
+	return n.Match(tags, available)
+}
+
+// Match - Return best available language of preferred tags by BCP 47 lookup (en-GB to en), fallback chain then default chain
+func (n *Negotiator) Match(tags []string, available []string) string {
+	set := map[string]string{}
+	for _, a := range available {
+		set[strings.ToLower(NormalizeTag(a))] = a
+	}
+	lookup := func(tag string) string {
+		// Truncate subtag from the end until a match
+		t := strings.ToLower(tag)
+		for t != "" {
+			if a, exist := set[t]; exist {
+				return a
+			}
+			i := strings.LastIndex(t, "-")
+			if i < 0 {
+				break
+			}
+			t = t[:i]
+		}
+		return ""
+	}
+
+	for _, tag := range tags {
+		if a := lookup(tag); a != "" {
+			return a
+		}
+		for _, f := range n.fallback(tag) {
+			if a := lookup(f); a != "" {
+				return a
+			}
+		}
+	}
+
+	// Available regional variant of preferred language, e.g. en to en-US
+	for _, tag := range tags {
+		base := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		for _, a := range available {
+			if strings.HasPrefix(strings.ToLower(NormalizeTag(a)), base+"-") {
+				return a
+			}
+		}
+	}
+
+	for _, d := range n.Default {
+		if a := lookup(d); a != "" {
+			return a
+		}
+	}
+	return ""
+}
+
+// fallback - Return fallback chain of tag or its base language
+func (n *Negotiator) fallback(tag string) []string {
+	if n.Fallback == nil {
+		return nil
+	}
+	t := strings.ToLower(tag)
+	for t != "" {
+		for k, v := range n.Fallback {
+			if strings.ToLower(NormalizeTag(k)) == t {
+				return v
+			}
+		}
+		i := strings.LastIndex(t, "-")
+		if i < 0 {
+			break
+		}
+		t = t[:i]
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package liblocale
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []LanguageQ
+	}{
+		{"", []LanguageQ{}},
+		{"en-us", []LanguageQ{{"en-US", 1}}},
+		{"en;q=0.5, id, fr;q=0.8", []LanguageQ{{"id", 1}, {"fr", 0.8}, {"en", 0.5}}},
+		{"en;q=0.8, id;q=0.8", []LanguageQ{{"en", 0.8}, {"id", 0.8}}},
+		{"en;q=0, id", []LanguageQ{{"id", 1}}},
+		{"*, en;q=0.1", []LanguageQ{{"en", 0.1}}},
+		{"en;q=2, fr;q=x, id;q=-1, ms", []LanguageQ{{"ms", 1}}},
+		{"zh_hant_tw ; q=0.9", []LanguageQ{{"zh-Hant-TW", 0.9}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	n := &Negotiator{
+		Fallback: map[string][]string{"ms": {"id"}},
+		Default:  []string{"en"},
+	}
+	tests := []struct {
+		name      string
+		n         *Negotiator
+		header    string
+		available []string
+		want      string
+	}{
+		{"exact", n, "id", []string{"en", "id"}, "id"},
+		{"highest q", n, "en;q=0.5, id;q=0.9", []string{"en", "id"}, "id"},
+		{"same q keep header order", n, "id;q=0.5, en;q=0.5", []string{"en", "id"}, "id"},
+		{"unavailable skipped", n, "fr, id;q=0.1", []string{"en", "id"}, "id"},
+		{"region to base", n, "en-US", []string{"en", "id"}, "en"},
+		{"script and region to base", n, "zh-Hant-TW", []string{"en", "zh-Hant"}, "zh-Hant"},
+		{"base to region", n, "en", []string{"en-GB", "id"}, "en-GB"},
+		{"case of available", n, "en-gb", []string{"EN_gb"}, "EN_gb"},
+		{"fallback chain", n, "ms-MY", []string{"en", "id"}, "id"},
+		{"fallback after lower q", n, "ms, en;q=0.1", []string{"en", "id"}, "id"},
+		{"default", n, "fr", []string{"en", "id"}, "en"},
+		{"q=0 excluded", n, "id;q=0, fr", []string{"en", "id"}, "en"},
+		{"q=0 not matched by region", n, "id-ID;q=0", []string{"en", "id"}, "en"},
+		{"empty header", n, "", []string{"en", "id"}, "en"},
+		{"nothing match", &Negotiator{}, "fr", []string{"en", "id"}, ""},
+		{"default unavailable", &Negotiator{Default: []string{"de", "id"}}, "fr", []string{"en", "id"}, "id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.n.Negotiate(tt.header, tt.available); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package liblocale
+
+import (
+	"math"
+	"strings"
+)
+
+// baseLanguage - Return lower case language subtag
+func baseLanguage(lang string) string {
+	return strings.ToLower(strings.SplitN(NormalizeTag(lang), "-", 2)[0])
+}
+
+// PluralCategory - Return CLDR cardinal plural category (zero, one, two, few, many, other) of number with visible decimals
+func PluralCategory(lang string, n float64, decimals int) string {
+	n = math.Abs(n)
+	i := int64(n)
+	v := decimals
+	isInt := n == math.Trunc(n)
+
+	switch baseLanguage(lang) {
+	case "id", "ms", "ja", "zh", "ko", "th", "vi", "lo", "my", "km":
+		return "other"
+	case "fr":
+		if i == 0 || i == 1 {
+			return "one"
+		}
+	case "pt":
+		if NormalizeTag(lang) == "pt-PT" {
+			if i == 1 && v == 0 {
+				return "one"
+			}
+			return "other"
+		}
+		if i == 0 || i == 1 {
+			return "one"
+		}
+	case "es", "tr":
+		if n == 1 {
+			return "one"
+		}
+	case "ru", "uk", "be":
+		if v != 0 {
+			return "other"
+		}
+		switch {
+		case i%10 == 1 && i%100 != 11:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "pl":
+		if v != 0 {
+			return "other"
+		}
+		switch {
+		case i == 1:
+			return "one"
+		case i%10 >= 2 && i%10 <= 4 && (i%100 < 12 || i%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	case "cs", "sk":
+		switch {
+		case v != 0:
+			return "many"
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		}
+	case "ar":
+		if !isInt {
+			return "other"
+		}
+		switch {
+		case i == 0:
+			return "zero"
+		case i == 1:
+			return "one"
+		case i == 2:
+			return "two"
+		case i%100 >= 3 && i%100 <= 10:
+			return "few"
+		case i%100 >= 11:
+			return "many"
+		}
+	default:
+		// en, de, nl, it, sv and most Germanic / Romance language
+		if i == 1 && v == 0 {
+			return "one"
+		}
+	}
+	return "other"
+}
+
+// OrdinalCategory - Return CLDR ordinal plural category of number, used by selectordinal
+func OrdinalCategory(lang string, n float64) string {
+	i := int64(math.Abs(n))
+	switch baseLanguage(lang) {
+	case "en":
+		switch {
+		case i%10 == 1 && i%100 != 11:
+			return "one"
+		case i%10 == 2 && i%100 != 12:
+			return "two"
+		case i%10 == 3 && i%100 != 13:
+			return "few"
+		}
+	case "fr":
+		if i == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
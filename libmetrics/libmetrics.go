@@ -0,0 +1,357 @@
+package libmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets - Default histogram buckets in seconds
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// collector - Metric written in Prometheus text exposition format
+type collector interface {
+	kind() string
+	help() string
+	labelNames() []string
+	write(w *bufio.Writer, name string)
+}
+
+// Registry - Registry of metrics
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]collector
+}
+
+// DefaultRegistry - Registry served by Handler
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry - Create registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]collector{}}
+}
+
+// register - Register metric or return existing metric with same name, kind and label names
+func (r *Registry) register(name string, c collector) collector {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, exist := r.metrics[name]; exist {
+		if old.kind() != c.kind() {
+			panic(fmt.Sprintf("metric %s already registered as %s", name, old.kind()))
+		}
+		if strings.Join(old.labelNames(), ",") != strings.Join(c.labelNames(), ",") {
+			// Label values of caller would be written under label names of existing metric
+			panic(fmt.Sprintf("metric %s already registered with labels [%s]", name, strings.Join(old.labelNames(), ", ")))
+		}
+		return old
+	}
+	r.metrics[name] = c
+	return c
+}
+
+// WriteText - Write every metric in Prometheus text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]collector, len(names))
+	for i, name := range names {
+		list[i] = r.metrics[name]
+	}
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	for i, name := range names {
+		c := list[i]
+		fmt.Fprintf(bw, "# HELP %s %s\n", name, escapeHelp(c.help()))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, c.kind())
+		c.write(bw, name)
+	}
+	return bw.Flush()
+}
+
+// Handler - Serve registry in Prometheus text exposition format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// Handler - Serve default registry
+func Handler() http.Handler {
+	return DefaultRegistry.Handler()
+}
+
+// vec - Series of metric by label values
+type vec struct {
+	desc   string
+	labels []string
+	mu     sync.Mutex
+	series map[string][]string // key to label values
+}
+
+// key - Return series key of label values
+func (v *vec) key(values []string) (string, []string) {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("expect %d label values, got %d", len(v.labels), len(values)))
+	}
+	k := strings.Join(values, "\xff")
+	if _, exist := v.series[k]; !exist {
+		cp := make([]string, len(values))
+		copy(cp, values)
+		v.series[k] = cp
+	}
+	return k, v.series[k]
+}
+
+// sortedKeys - Return series keys in order
+func (v *vec) sortedKeys() []string {
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// help -
+func (v *vec) help() string {
+	return v.desc
+}
+
+// labelNames -
+func (v *vec) labelNames() []string {
+	return v.labels
+}
+
+// Counter - Monotonic counter
+type Counter struct {
+	vec
+	values map[string]float64
+}
+
+// NewCounter - Create counter in default registry
+func NewCounter(name string, help string, labels ...string) *Counter {
+	return DefaultRegistry.Counter(name, help, labels...)
+}
+
+// Counter - Create counter or return existing counter with same name
+func (r *Registry) Counter(name string, help string, labels ...string) *Counter {
+	c := &Counter{
+		vec:    vec{desc: help, labels: labels, series: map[string][]string{}},
+		values: map[string]float64{},
+	}
+	return r.register(name, c).(*Counter)
+}
+
+// Inc - Increase counter by 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add - Increase counter by v, negative value is ignored
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k, _ := c.key(labelValues)
+	c.values[k] += v
+}
+
+// kind -
+func (c *Counter) kind() string {
+	return "counter"
+}
+
+// write -
+func (c *Counter) write(w *bufio.Writer, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(c.labels, c.series[k], "", ""), formatValue(c.values[k]))
+	}
+}
+
+// Gauge - Value that can go up and down
+type Gauge struct {
+	vec
+	values map[string]float64
+}
+
+// NewGauge - Create gauge in default registry
+func NewGauge(name string, help string, labels ...string) *Gauge {
+	return DefaultRegistry.Gauge(name, help, labels...)
+}
+
+// Gauge - Create gauge or return existing gauge with same name
+func (r *Registry) Gauge(name string, help string, labels ...string) *Gauge {
+	g := &Gauge{
+		vec:    vec{desc: help, labels: labels, series: map[string][]string{}},
+		values: map[string]float64{},
+	}
+	return r.register(name, g).(*Gauge)
+}
+
+// Set - Set gauge value
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	k, _ := g.key(labelValues)
+	g.values[k] = v
+}
+
+// Add - Add v to gauge value
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	k, _ := g.key(labelValues)
+	g.values[k] += v
+}
+
+// Inc - Increase gauge by 1
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec - Decrease gauge by 1
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// kind -
+func (g *Gauge) kind() string {
+	return "gauge"
+}
+
+// write -
+func (g *Gauge) write(w *bufio.Writer, name string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, k := range g.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(g.labels, g.series[k], "", ""), formatValue(g.values[k]))
+	}
+}
+
+// Histogram - Distribution of observed values in buckets
+type Histogram struct {
+	vec
+	buckets []float64
+	counts  map[string][]uint64
+	sums    map[string]float64
+	totals  map[string]uint64
+}
+
+// NewHistogram - Create histogram in default registry, nil buckets use DefBuckets
+func NewHistogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	return DefaultRegistry.Histogram(name, help, buckets, labels...)
+}
+
+// Histogram - Create histogram or return existing histogram with same name
+func (r *Registry) Histogram(name string, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	b := make([]float64, len(buckets))
+	copy(b, buckets)
+	sort.Float64s(b)
+	h := &Histogram{
+		vec:     vec{desc: help, labels: labels, series: map[string][]string{}},
+		buckets: b,
+		counts:  map[string][]uint64{},
+		sums:    map[string]float64{},
+		totals:  map[string]uint64{},
+	}
+	return r.register(name, h).(*Histogram)
+}
+
+// Observe - Add observed value
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k, _ := h.key(labelValues)
+	counts, exist := h.counts[k]
+	if !exist {
+		counts = make([]uint64, len(h.buckets))
+		h.counts[k] = counts
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			counts[i]++
+		}
+	}
+	h.sums[k] += v
+	h.totals[k]++
+}
+
+// kind -
+func (h *Histogram) kind() string {
+	return "histogram"
+}
+
+// write -
+func (h *Histogram) write(w *bufio.Writer, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, k := range h.sortedKeys() {
+		values := h.series[k]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(h.labels, values, "le", formatValue(upper)), h.counts[k][i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(h.labels, values, "le", "+Inf"), h.totals[k])
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, formatLabels(h.labels, values, "", ""), formatValue(h.sums[k]))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, formatLabels(h.labels, values, "", ""), h.totals[k])
+	}
+}
+
+// formatLabels - Format {label="value",...} with optional extra label
+func formatLabels(labels []string, values []string, extra string, extraValue string) string {
+	list := []string{}
+	for i, l := range labels {
+		list = append(list, l+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extra != "" {
+		list = append(list, extra+`="`+escapeLabel(extraValue)+`"`)
+	}
+	if len(list) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(list, ",") + "}"
+}
+
+// formatValue - Format float value
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabel - Escape backslash, double quote and new line of label value
+func escapeLabel(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
+
+// escapeHelp - Escape backslash and new line of help text
+func escapeHelp(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
@@ -0,0 +1,76 @@
+package libmetrics
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(r *Registry) interface{}
+		same     bool
+		panic    string
+	}{
+		{"same labels", func(r *Registry) interface{} { return r.Counter("requests_total", "Other help", "method", "status") }, true, ""},
+		{"other name", func(r *Registry) interface{} { return r.Counter("errors_total", "Errors", "method", "status") }, false, ""},
+		{"other kind", func(r *Registry) interface{} { return r.Gauge("requests_total", "Requests", "method", "status") }, false, "metric requests_total already registered as counter"},
+		{"other label order", func(r *Registry) interface{} { return r.Counter("requests_total", "Requests", "status", "method") }, false, "metric requests_total already registered with labels [method, status]"},
+		{"fewer labels", func(r *Registry) interface{} { return r.Counter("requests_total", "Requests", "method") }, false, "metric requests_total already registered with labels [method, status]"},
+		{"no label", func(r *Registry) interface{} { return r.Counter("requests_total", "Requests") }, false, "metric requests_total already registered with labels [method, status]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			c := r.Counter("requests_total", "Requests", "method", "status")
+			defer func() {
+				got := ""
+				if v := recover(); v != nil {
+					got = fmt.Sprint(v)
+				}
+				if got != tt.panic {
+					t.Errorf("panic = %q, want %q", got, tt.panic)
+				}
+			}()
+			m := tt.register(r)
+			if same := m == interface{}(c); same != tt.same {
+				t.Errorf("same metric = %v, want %v", same, tt.same)
+			}
+		})
+	}
+}
+
+func TestRegistryWriteText(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("requests_total", "Total\nrequests", "method")
+	c.Inc("GET")
+	c.Add(2, `P"O\ST`)
+	g := r.Gauge("in_flight", "In flight")
+	g.Inc()
+	h := r.Histogram("latency_seconds", "Latency", []float64{1, 0.5})
+	h.Observe(0.7)
+
+	var b bytes.Buffer
+	if err := r.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP in_flight In flight
+# TYPE in_flight gauge
+in_flight 1
+# HELP latency_seconds Latency
+# TYPE latency_seconds histogram
+latency_seconds_bucket{le="0.5"} 0
+latency_seconds_bucket{le="1"} 1
+latency_seconds_bucket{le="+Inf"} 1
+latency_seconds_sum 0.7
+latency_seconds_count 1
+# HELP requests_total Total\nrequests
+# TYPE requests_total counter
+requests_total{method="GET"} 1
+requests_total{method="P\"O\\ST"} 2
+`
+	if b.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,111 @@
+package libquery
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Filters - Build configs and params from request struct filter tags, e.g. `filter:"like,column=module_name"`
+// Options: column=, param=, value=, mode= (fulltext), collation=, min= (length) and time (parse value in tz)
+func Filters(r interface{}, tz string) ([]Config, map[string]interface{}) {
+	configs := []Config{}
+	params := map[string]interface{}{}
+
+	rVal := reflect.ValueOf(r)
+	for rVal.Kind() == reflect.Ptr {
+		if rVal.IsNil() {
+			return configs, params
+		}
+		rVal = rVal.Elem()
+	}
+	if rVal.Kind() != reflect.Struct {
+		return configs, params
+	}
+	collectFilters(rVal, tz, &configs, params)
+	return configs, params
+}
+
+// FilterCondition - Append conditions from request struct filter tags
+func FilterCondition(r interface{}, tz string, condition string, values map[string]interface{}) (string, map[string]interface{}, error) {
+	configs, params := Filters(r, tz)
+	for _, cfg := range configs {
+		var err error
+		condition, values, err = QueryCondition(cfg, params, condition, values)
+		if err != nil {
+			return condition, values, err
+		}
+	}
+	return condition, values, nil
+}
+
+// collectFilters - Walk struct fields including embedded struct
+func collectFilters(rVal reflect.Value, tz string, configs *[]Config, params map[string]interface{}) {
+	rType := rVal.Type()
+	for i := 0; i < rVal.NumField(); i++ {
+		field := rType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := field.Tag.Get("filter")
+		if tag == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			collectFilters(rVal.Field(i), tz, configs, params)
+			continue
+		}
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+
+		opts := strings.Split(tag, ",")
+		cfg := Config{
+			Condition: strings.TrimSpace(opts[0]),
+			Param:     name,
+		}
+		for _, opt := range opts[1:] {
+			kv := strings.SplitN(strings.TrimSpace(opt), "=", 2)
+			switch kv[0] {
+			case "column":
+				if len(kv) == 2 {
+					cfg.Column = kv[1]
+					if !strings.ContainsAny(cfg.Column, "`.( ") {
+						cfg.Column = "`" + cfg.Column + "`"
+					}
+				}
+			case "param":
+				if len(kv) == 2 {
+					cfg.Param = kv[1]
+				}
+			case "value":
+				if len(kv) == 2 {
+					cfg.ColumnValue = kv[1]
+				}
+			case "mode":
+				if len(kv) == 2 {
+					cfg.FullTextMode = kv[1]
+				}
+			case "collation":
+				if len(kv) == 2 {
+					cfg.Collation = kv[1]
+				}
+			case "min":
+				if len(kv) == 2 {
+					cfg.MinLength, _ = strconv.Atoi(kv[1])
+				}
+			case "time":
+				cfg.Timezone = tz
+				if cfg.Timezone == "" {
+					cfg.Timezone = "UTC"
+				}
+			}
+		}
+
+		*configs = append(*configs, cfg)
+		params[cfg.Param] = rVal.Field(i).Interface()
+	}
+}
@@ -0,0 +1,59 @@
+package libquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterCondition(t *testing.T) {
+	type Embedded struct {
+		Remark string `json:"remark" filter:"like"`
+	}
+	type request struct {
+		Embedded
+		ID         []int64 `json:"id" filter:"in"`
+		ModuleName string  `json:"module_name" filter:"equal,column=module_name,collation=utf8mb4_unicode_ci"`
+		Label      string  `json:"label" filter:"starts_with,param=name"`
+		Page       int64   `json:"page"`
+		Skip       string  `json:"skip" filter:"-"`
+	}
+	tests := []struct {
+		name      string
+		r         interface{}
+		condition string
+		values    map[string]interface{}
+	}{
+		{
+			name:      "every filter",
+			r:         &request{Embedded: Embedded{Remark: "a%"}, ID: []int64{1, 2}, ModuleName: "audit", Label: "x", Page: 2, Skip: "y"},
+			condition: "AND `remark` LIKE :remark ESCAPE '!' AND `id` IN (:id_in_0, :id_in_1) AND `module_name` COLLATE utf8mb4_unicode_ci = :module_name AND `name` LIKE :name ESCAPE '!' ",
+			values:    map[string]interface{}{"remark": "%a!%%", "id_in_0": int64(1), "id_in_1": int64(2), "module_name": "audit", "name": "x%"},
+		},
+		{
+			name:      "empty value skipped",
+			r:         request{},
+			condition: "",
+			values:    map[string]interface{}{},
+		},
+		{
+			name:      "nil request",
+			r:         (*request)(nil),
+			condition: "",
+			values:    map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, values, err := FilterCondition(tt.r, "", "", map[string]interface{}{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if condition != tt.condition {
+				t.Errorf("condition = %q, want %q", condition, tt.condition)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values = %v, want %v", values, tt.values)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package libquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Node - Condition tree node rendered to named parameter SQL fragment
+type Node interface {
+	// Render - Render node, bind its values and return fragment without leading "AND", empty if skipped
+	Render(values map[string]interface{}) (string, error)
+}
+
+// condNode - Leaf node of single Config
+type condNode struct {
+	cfg    Config
+	params map[string]interface{}
+}
+
+// rawNode - Leaf node of raw SQL fragment
+type rawNode struct {
+	query  string
+	values map[string]interface{}
+}
+
+// groupNode - Group of nodes joined with AND / OR
+type groupNode struct {
+	op    string
+	nodes []Node
+}
+
+// notNode - Negate node
+type notNode struct {
+	node Node
+}
+
+// namedNode - Reference to registered condition
+type namedNode struct {
+	name string
+}
+
+var namedConditions = map[string]Node{}
+var namedMutex sync.RWMutex
+
+// Cond - Condition node of single Config evaluated against params, skipped on empty value like QueryCondition
+func Cond(cfg Config, params map[string]interface{}) Node {
+	return &condNode{cfg: cfg, params: params}
+}
+
+// Raw - Condition node of raw SQL fragment with its named values
+func Raw(query string, values map[string]interface{}) Node {
+	return &rawNode{query: query, values: values}
+}
+
+// And - Group nodes with AND
+func And(nodes ...Node) Node {
+	return &groupNode{op: "AND", nodes: nodes}
+}
+
+// Or - Group nodes with OR
+func Or(nodes ...Node) Node {
+	return &groupNode{op: "OR", nodes: nodes}
+}
+
+// Not - Negate node
+func Not(node Node) Node {
+	return &notNode{node: node}
+}
+
+// Register - Register reusable named condition
+func Register(name string, node Node) {
+	namedMutex.Lock()
+	defer namedMutex.Unlock()
+	namedConditions[name] = node
+}
+
+// Named - Reference registered condition by name, resolved when rendered
+func Named(name string) Node {
+	return &namedNode{name: name}
+}
+
+// Build - Render node and append it to condition as "AND (...) "
+func Build(node Node, condition string, values map[string]interface{}) (string, map[string]interface{}, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	s, err := node.Render(values)
+	if err != nil {
+		return condition, values, err
+	}
+	if s != "" {
+		condition += "AND " + s + " "
+	}
+	return condition, values, nil
+}
+
+// Render -
+func (n *condNode) Render(values map[string]interface{}) (string, error) {
+	s, _, err := QueryCondition(n.cfg, n.params, "", values)
+	if err != nil || s == "" {
+		return "", err
+	}
+	s = strings.TrimSpace(strings.TrimPrefix(s, "AND "))
+	if strings.Contains(s, " AND ") {
+		// Operator rendered more than one clause, e.g. between
+		s = "(" + s + ")"
+	}
+	return s, nil
+}
+
+// Render -
+func (n *rawNode) Render(values map[string]interface{}) (string, error) {
+	for k, v := range n.values {
+		if old, exist := values[k]; exist && !reflect.DeepEqual(old, v) {
+			return "", fmt.Errorf("%s", "general.error_query_placeholder_collision")
+		}
+		values[k] = v
+	}
+	s := strings.TrimSpace(n.query)
+	if s == "" {
+		return "", nil
+	}
+	return "(" + s + ")", nil
+}
+
+// Render -
+func (n *groupNode) Render(values map[string]interface{}) (string, error) {
+	list := []string{}
+	for _, node := range n.nodes {
+		if node == nil {
+			continue
+		}
+		s, err := node.Render(values)
+		if err != nil {
+			return "", err
+		}
+		if s != "" {
+			list = append(list, s)
+		}
+	}
+	switch len(list) {
+	case 0:
+		return "", nil
+	case 1:
+		return list[0], nil
+	}
+	return "(" + strings.Join(list, " "+n.op+" ") + ")", nil
+}
+
+// Render -
+func (n *notNode) Render(values map[string]interface{}) (string, error) {
+	if n.node == nil {
+		return "", nil
+	}
+	s, err := n.node.Render(values)
+	if err != nil || s == "" {
+		return "", err
+	}
+	return "NOT (" + s + ")", nil
+}
+
+// Render -
+func (n *namedNode) Render(values map[string]interface{}) (string, error) {
+	namedMutex.RLock()
+	node, exist := namedConditions[n.name]
+	namedMutex.RUnlock()
+	if !exist {
+		return "", fmt.Errorf("%s", "general.error_query_condition_not_found")
+	}
+	return node.Render(values)
+}
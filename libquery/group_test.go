@@ -0,0 +1,119 @@
+package libquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	equal := func(param string, v interface{}) Node {
+		return Cond(Config{Condition: "equal", Param: param}, map[string]interface{}{param: v})
+	}
+	Register("test_active", And(equal("status", 1), Raw("deleted_at IS NULL", nil)))
+
+	tests := []struct {
+		name      string
+		node      Node
+		condition string
+		values    map[string]interface{}
+		err       string
+	}{
+		{
+			name:      "nested and or",
+			node:      And(equal("status", 1), Or(equal("role", "admin"), And(equal("team", 2), equal("lead", 1)))),
+			condition: "AND (`status` = :status AND (`role` = :role OR (`team` = :team AND `lead` = :lead))) ",
+			values:    map[string]interface{}{"status": 1, "role": "admin", "team": 2, "lead": 1},
+		},
+		{
+			name:      "single node without parenthesis",
+			node:      Or(equal("status", 1), equal("role", "")),
+			condition: "AND `status` = :status ",
+			values:    map[string]interface{}{"status": 1},
+		},
+		{
+			name:      "empty group skipped",
+			node:      And(Or(equal("role", ""), nil), Not(equal("status", nil))),
+			condition: "",
+			values:    map[string]interface{}{},
+		},
+		{
+			name:      "between of one node in parenthesis",
+			node:      Or(Cond(Config{Condition: "between", Param: "age"}, map[string]interface{}{"age": []int{1, 9}}), equal("role", "admin")),
+			condition: "AND ((`age` >= :age_from AND `age` <= :age_to) OR `role` = :role) ",
+			values:    map[string]interface{}{"age_from": 1, "age_to": 9, "role": "admin"},
+		},
+		{
+			name:      "not",
+			node:      And(Not(equal("status", 1)), Not(Or(equal("role", "admin"), equal("team", 2))), Not(nil)),
+			condition: "AND (NOT (`status` = :status) AND NOT ((`role` = :role OR `team` = :team))) ",
+			values:    map[string]interface{}{"status": 1, "role": "admin", "team": 2},
+		},
+		{
+			name:      "placeholder of same field not collide",
+			node:      Or(equal("role", "admin"), equal("role", "user"), Cond(Config{Condition: "in", Param: "role"}, map[string]interface{}{"role": []string{"a"}})),
+			condition: "AND (`role` = :role OR `role` = :role_2 OR `role` IN (:role_in_0)) ",
+			values:    map[string]interface{}{"role": "admin", "role_2": "user", "role_in_0": "a"},
+		},
+		{
+			name:      "raw value merged",
+			node:      And(Raw("owner_id = :owner", map[string]interface{}{"owner": 7}), Raw(" ", nil), Raw("editor_id = :owner", map[string]interface{}{"owner": 7}), equal("owner", 8)),
+			condition: "AND ((owner_id = :owner) AND (editor_id = :owner) AND `owner` = :owner_2) ",
+			values:    map[string]interface{}{"owner": 7, "owner_2": 8},
+		},
+		{
+			name: "raw value collision",
+			node: And(equal("owner", 8), Raw("owner_id = :owner", map[string]interface{}{"owner": 7})),
+			err:  "general.error_query_placeholder_collision",
+		},
+		{
+			name:      "named",
+			node:      Or(Named("test_active"), equal("role", "admin")),
+			condition: "AND ((`status` = :status AND (deleted_at IS NULL)) OR `role` = :role) ",
+			values:    map[string]interface{}{"status": 1, "role": "admin"},
+		},
+		{
+			name: "unknown named",
+			node: And(equal("status", 1), Not(Named("test_unknown"))),
+			err:  "general.error_query_condition_not_found",
+		},
+		{
+			name: "cond validation error",
+			node: Or(Cond(Config{Condition: "between", Param: "age"}, map[string]interface{}{"age": "1"})),
+			err:  "general.error_validation_range",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, values, err := Build(tt.node, "", nil)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("error = %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if condition != tt.condition {
+				t.Errorf("condition = %q, want %q", condition, tt.condition)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values = %v, want %v", values, tt.values)
+			}
+		})
+	}
+}
+
+func TestBuildAppend(t *testing.T) {
+	values := map[string]interface{}{"status": 0}
+	condition, values, err := Build(Cond(Config{Condition: "equal", Param: "status"}, map[string]interface{}{"status": 1}), "AND `status` != :status ", values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "AND `status` != :status AND `status` = :status_2 "; condition != want {
+		t.Errorf("condition = %q, want %q", condition, want)
+	}
+	if want := map[string]interface{}{"status": 0, "status_2": 1}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values = %v, want %v", values, want)
+	}
+}
@@ -3,21 +3,89 @@ package libquery
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/helloferdie/stdgo/libresponse"
 )
 
 // Config -
 type Config struct {
-	Condition   string
-	Param       string
-	Column      string
-	ColumnValue string
+	Condition    string
+	Param        string
+	Column       string
+	ColumnValue  string
+	Timezone     string // Parse time value in timezone, e.g. request Accept-TZ
+	FullTextMode string // Search modifier for fulltext, e.g. "IN BOOLEAN MODE"
+	Collation    string // Collation for equal and like search, e.g. "utf8mb4_unicode_ci" for case/accent insensitive
+	MinLength    int    // Minimum length of search value, shorter value return validation error
+}
+
+// ValidationError - Filter value not valid, Key and Var are locale syntax and its variable
+type ValidationError struct {
+	Param string
+	Loc   string // Locale namespace of param label, default general
+	Key   string
+	Var   []interface{}
+}
+
+// Error -
+func (e *ValidationError) Error() string {
+	return e.Key
+}
+
+// Response - Return validation error response, error is <key>_var with param label like libvalidator
+func (e *ValidationError) Response() *libresponse.Default {
+	loc := e.Loc
+	if loc == "" {
+		loc = "general"
+	}
+	res := libresponse.GetDefault()
+	res.Code = 422
+	res.Message = "general.error_validation"
+	res.Error = e.Key + "_var"
+	res.ErrorVar = append([]interface{}{loc + ".var_" + e.Param}, e.Var...)
+	res.Data = map[string]interface{}{
+		e.Param: map[string]interface{}{
+			"error":     e.Key,
+			"error_var": e.Var,
+		},
+	}
+	return res
 }
 
+// likeEscape - Escape character of LIKE, bound value is not parsed as string literal so backslash has no special meaning
+var likeEscape = "!"
+
+// Regex for valid collation name
+var regexCollation = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// EscapeLike - Escape LIKE wildcard % and _ with escape character "!"
+func EscapeLike(s string) string {
+	s = strings.ReplaceAll(s, likeEscape, likeEscape+likeEscape)
+	s = strings.ReplaceAll(s, "%", likeEscape+"%")
+	s = strings.ReplaceAll(s, "_", likeEscape+"_")
+	return s
+}
+
+// Time layouts accepted for time value
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// dateLayout - Layout of date only value
+var dateLayout = "2006-01-02"
+
 // QueryCondition -
 func QueryCondition(cfg Config, params map[string]interface{}, condition string, values map[string]interface{}) (string, map[string]interface{}, error) {
 	paramVal, paramExist := params[cfg.Param]
-	if paramExist {
+	if paramExist && paramVal != nil {
 		// Set default if not define
 		if cfg.Column == "" {
 			cfg.Column = "`" + cfg.Param + "`"
@@ -28,31 +96,120 @@ func QueryCondition(cfg Config, params map[string]interface{}, condition string,
 
 		//dt := reflect.TypeOf(paramVal).String()
 		dk := reflect.TypeOf(paramVal).Kind()
-		if cfg.Condition == "equal" {
+		notEmpty := dk != reflect.String || (dk == reflect.String && paramVal.(string) != "")
+
+		column := cfg.Column
+		if cfg.Collation != "" {
+			if !regexCollation.MatchString(cfg.Collation) {
+				return condition, values, &ValidationError{
+					Param: cfg.Param,
+					Key:   "general.error_validation_collation",
+					Var:   []interface{}{"!" + cfg.Collation},
+				}
+			}
+			column += " COLLATE " + cfg.Collation
+		}
+		if notEmpty && cfg.MinLength > 0 && dk == reflect.String && utf8.RuneCountInString(strings.TrimSpace(paramVal.(string))) < cfg.MinLength {
+			return condition, values, &ValidationError{
+				Param: cfg.Param,
+				Key:   "general.error_validation_min",
+				Var:   []interface{}{"!" + strconv.Itoa(cfg.MinLength)},
+			}
+		}
+
+		switch cfg.Condition {
+		case "equal":
 			// "AND col = val "
-			if dk != reflect.String || (dk == reflect.String && paramVal.(string) != "") {
-				condition += fmt.Sprintf("AND %s = :%s ", cfg.Column, cfg.ColumnValue)
-				values[cfg.ColumnValue] = paramVal
+			if notEmpty {
+				condition += fmt.Sprintf("AND %s = :%s ", column, bind(values, cfg.ColumnValue, paramVal))
 			}
-		} else if cfg.Condition == "not_equal" {
+		case "not_equal":
 			// "AND col != val "
-			if dk != reflect.String || (dk == reflect.String && paramVal.(string) != "") {
-				condition += fmt.Sprintf("AND %s != :%s ", cfg.Column, cfg.ColumnValue)
-				values[cfg.ColumnValue] = paramVal
+			if notEmpty {
+				condition += fmt.Sprintf("AND %s != :%s ", column, bind(values, cfg.ColumnValue, paramVal))
 			}
-		} else if cfg.Condition == "like" {
+		case "like":
 			// "AND col LIKE %val% "
-			if dk != reflect.String || (dk == reflect.String && paramVal.(string) != "") {
-				condition += fmt.Sprintf("AND %s LIKE :%s ", cfg.Column, cfg.ColumnValue)
-				values[cfg.ColumnValue] = fmt.Sprintf("%%%v%%", paramVal)
+			if notEmpty {
+				condition += fmt.Sprintf("AND %s LIKE :%s ESCAPE '%s' ", column, bind(values, cfg.ColumnValue, "%"+EscapeLike(fmt.Sprintf("%v", paramVal))+"%"), likeEscape)
+			}
+		case "like_match":
+			// "AND col LIKE val ", value is pattern as is
+			if notEmpty {
+				condition += fmt.Sprintf("AND %s LIKE :%s ", column, bind(values, cfg.ColumnValue, paramVal))
+			}
+		case "starts_with":
+			// "AND col LIKE val% "
+			if notEmpty {
+				condition += fmt.Sprintf("AND %s LIKE :%s ESCAPE '%s' ", column, bind(values, cfg.ColumnValue, EscapeLike(fmt.Sprintf("%v", paramVal))+"%"), likeEscape)
+			}
+		case "ends_with":
+			// "AND col LIKE %val "
+			if notEmpty {
+				condition += fmt.Sprintf("AND %s LIKE :%s ESCAPE '%s' ", column, bind(values, cfg.ColumnValue, "%"+EscapeLike(fmt.Sprintf("%v", paramVal))), likeEscape)
+			}
+		case "gt", "gte", "lt", "lte":
+			// "AND col > val ", "AND col >= val ", "AND col < val ", "AND col <= val "
+			if notEmpty {
+				v, _, err := parseValue(cfg.Param, paramVal, cfg.Timezone)
+				if err != nil {
+					return condition, values, err
+				}
+				op := map[string]string{"gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[cfg.Condition]
+				condition += fmt.Sprintf("AND %s %s :%s ", cfg.Column, op, bind(values, cfg.ColumnValue, v))
+			}
+		case "between":
+			// "AND col >= val1 AND col <= val2 ", either bound may be empty
+			from, to, ok := rangeValue(paramVal)
+			if !ok {
+				return condition, values, &ValidationError{Param: cfg.Param, Key: "general.error_validation_range", Var: []interface{}{}}
+			}
+			if !isEmpty(from) {
+				v, _, err := parseValue(cfg.Param, from, cfg.Timezone)
+				if err != nil {
+					return condition, values, err
+				}
+				condition += fmt.Sprintf("AND %s >= :%s ", cfg.Column, bind(values, cfg.ColumnValue+"_from", v))
+			}
+			if !isEmpty(to) {
+				v, isDate, err := parseValue(cfg.Param, to, cfg.Timezone)
+				if err != nil {
+					return condition, values, err
+				}
+				if isDate {
+					// Date only upper bound covers the whole day, in timezone or as date string without timezone
+					if t, ok := v.(time.Time); ok {
+						loc, _ := time.LoadLocation(cfg.Timezone)
+						v = t.In(loc).AddDate(0, 0, 1).UTC()
+					} else {
+						t, _ := time.Parse(dateLayout, v.(string))
+						v = t.AddDate(0, 0, 1).Format(dateLayout)
+					}
+					condition += fmt.Sprintf("AND %s < :%s ", cfg.Column, bind(values, cfg.ColumnValue+"_to", v))
+				} else {
+					condition += fmt.Sprintf("AND %s <= :%s ", cfg.Column, bind(values, cfg.ColumnValue+"_to", v))
+				}
+			}
+		case "is_null":
+			// "AND col IS NULL "
+			if isTrue(paramVal) {
+				condition += fmt.Sprintf("AND %s IS NULL ", cfg.Column)
 			}
-		} else if cfg.Condition == "like_match" {
-			// "AND col LIKE val "
-			if dk != reflect.String || (dk == reflect.String && paramVal.(string) != "") {
-				condition += fmt.Sprintf("AND %s LIKE :%s ", cfg.Column, cfg.ColumnValue)
-				values[cfg.ColumnValue] = paramVal
+		case "not_null":
+			// "AND col IS NOT NULL "
+			if isTrue(paramVal) {
+				condition += fmt.Sprintf("AND %s IS NOT NULL ", cfg.Column)
+			}
+		case "fulltext":
+			// "AND MATCH (col) AGAINST (val) "
+			if notEmpty {
+				mode := ""
+				if cfg.FullTextMode != "" {
+					mode = " " + cfg.FullTextMode
+				}
+				condition += fmt.Sprintf("AND MATCH (%s) AGAINST (:%s%s) ", cfg.Column, bind(values, cfg.ColumnValue, paramVal), mode)
 			}
-		} else if cfg.Condition == "in" {
+		case "in":
 			// "AND col IN (:val1, :val2, ...)"
 			if dk == reflect.Slice {
 				s := reflect.ValueOf(paramVal)
@@ -62,14 +219,12 @@ func QueryCondition(cfg Config, params map[string]interface{}, condition string,
 						if i != 0 {
 							syntax += ", "
 						}
-						colVal := cfg.ColumnValue + "_in_" + strconv.Itoa(i)
-						syntax += ":" + colVal
-						values[colVal] = s.Index(i).Interface()
+						syntax += ":" + bind(values, cfg.ColumnValue+"_in_"+strconv.Itoa(i), s.Index(i).Interface())
 					}
 					condition += fmt.Sprintf("AND %s IN (%s) ", cfg.Column, syntax)
 				}
 			}
-		} else if cfg.Condition == "not in" {
+		case "not in":
 			// "AND col NOT IN (:val1, :val2, ...)"
 			if dk == reflect.Slice {
 				s := reflect.ValueOf(paramVal)
@@ -79,9 +234,7 @@ func QueryCondition(cfg Config, params map[string]interface{}, condition string,
 						if i != 0 {
 							syntax += ", "
 						}
-						colVal := cfg.ColumnValue + "_in_" + strconv.Itoa(i)
-						syntax += ":" + colVal
-						values[colVal] = s.Index(i).Interface()
+						syntax += ":" + bind(values, cfg.ColumnValue+"_in_"+strconv.Itoa(i), s.Index(i).Interface())
 					}
 					condition += fmt.Sprintf("AND %s NOT IN (%s) ", cfg.Column, syntax)
 				}
@@ -90,3 +243,91 @@ func QueryCondition(cfg Config, params map[string]interface{}, condition string,
 	}
 	return condition, values, nil
 }
+
+// bind - Set value under placeholder name not yet used in values and return the name
+func bind(values map[string]interface{}, name string, v interface{}) string {
+	key := name
+	for i := 2; ; i++ {
+		if _, exist := values[key]; !exist {
+			break
+		}
+		key = name + "_" + strconv.Itoa(i)
+	}
+	values[key] = v
+	return key
+}
+
+// isEmpty - Check value is nil or empty string
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && s == ""
+}
+
+// isTrue - Check value is set for flag condition (is_null, not_null)
+func isTrue(v interface{}) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case string:
+		b, err := strconv.ParseBool(t)
+		if err != nil {
+			return t != ""
+		}
+		return b
+	}
+	return !isEmpty(v)
+}
+
+// rangeValue - Return lower and upper bound from slice or comma separated string
+func rangeValue(v interface{}) (interface{}, interface{}, bool) {
+	if s, ok := v.(string); ok {
+		if s == "" {
+			return nil, nil, true
+		}
+		split := strings.SplitN(s, ",", 2)
+		if len(split) != 2 {
+			return nil, nil, false
+		}
+		return strings.TrimSpace(split[0]), strings.TrimSpace(split[1]), true
+	}
+
+	rVal := reflect.ValueOf(v)
+	if rVal.Kind() != reflect.Slice && rVal.Kind() != reflect.Array {
+		return nil, nil, false
+	}
+	if rVal.Len() == 0 {
+		return nil, nil, true
+	}
+	if rVal.Len() != 2 {
+		return nil, nil, false
+	}
+	return rVal.Index(0).Interface(), rVal.Index(1).Interface(), true
+}
+
+// parseValue - Parse string time value in timezone and convert to UTC, other value or value without timezone return as is,
+// also return whether value is date only
+func parseValue(param string, v interface{}, tz string) (interface{}, bool, error) {
+	s, ok := v.(string)
+	if !ok {
+		return v, false, nil
+	}
+	if tz == "" {
+		_, err := time.Parse(dateLayout, s)
+		return v, err == nil, nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, false, &ValidationError{Param: param, Key: "general.error_validation_timezone", Var: []interface{}{"!" + tz}}
+	}
+	for _, layout := range timeLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t.UTC(), layout == dateLayout, nil
+		}
+	}
+	return nil, false, &ValidationError{Param: param, Key: "general.error_validation_datetime", Var: []interface{}{}}
+}
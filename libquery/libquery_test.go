@@ -0,0 +1,91 @@
+package libquery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryConditionValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		value  interface{}
+		key    string
+		substr string
+	}{
+		{name: "like escaped", cfg: Config{Condition: "like", Param: "label"}, value: "50%_off", substr: "LIKE"},
+		{name: "collation", cfg: Config{Condition: "equal", Param: "label", Collation: "utf8mb4_unicode_ci"}, value: "en", substr: "COLLATE utf8mb4_unicode_ci"},
+		{name: "invalid collation", cfg: Config{Condition: "equal", Param: "label", Collation: "x; DROP"}, value: "en", key: "general.error_validation_collation"},
+		{name: "min length", cfg: Config{Condition: "like", Param: "label", MinLength: 3}, value: "ab", key: "general.error_validation_min"},
+		{name: "invalid timezone", cfg: Config{Condition: "gte", Param: "created_at", Timezone: "Mars/Base"}, value: "2021-01-01", key: "general.error_validation_timezone"},
+		{name: "invalid datetime", cfg: Config{Condition: "gte", Param: "created_at", Timezone: "UTC"}, value: "yesterday", key: "general.error_validation_datetime"},
+		{name: "timezone", cfg: Config{Condition: "gte", Param: "created_at", Timezone: "Asia/Jakarta"}, value: "2021-01-01", substr: ">="},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, values, err := QueryCondition(tt.cfg, map[string]interface{}{tt.cfg.Param: tt.value}, "", map[string]interface{}{})
+			if tt.key != "" {
+				ve, ok := err.(*ValidationError)
+				if !ok || ve.Key != tt.key || ve.Param != tt.cfg.Param {
+					t.Fatalf("err = %#v, want ValidationError %s", err, tt.key)
+				}
+				res := ve.Response()
+				if res.Code != 422 || res.Error != tt.key+"_var" || len(res.ErrorVar) == 0 || res.ErrorVar[0] != "general.var_"+tt.cfg.Param {
+					t.Errorf("Response() = %+v", res)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !strings.Contains(condition, tt.substr) || len(values) == 0 {
+				t.Errorf("condition = %q values = %v, want %q", condition, values, tt.substr)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got := EscapeLike("50%_off!"); got != "50!%!_off!!" {
+		t.Errorf("EscapeLike() = %q", got)
+	}
+}
+
+func TestQueryConditionBetween(t *testing.T) {
+	const both = "AND `created_at` >= :created_at_from AND `created_at` < :created_at_to "
+	tests := []struct {
+		name      string
+		tz        string
+		value     interface{}
+		condition string
+		values    map[string]interface{}
+	}{
+		{"date without timezone", "", "2021-01-01,2021-01-31", both, map[string]interface{}{"created_at_from": "2021-01-01", "created_at_to": "2021-02-01"}},
+		{"date of year end without timezone", "", []string{"", "2021-12-31"}, "AND `created_at` < :created_at_to ", map[string]interface{}{"created_at_to": "2022-01-01"}},
+		{"datetime without timezone", "", "2021-01-01,2021-01-31 10:00:00", "AND `created_at` >= :created_at_from AND `created_at` <= :created_at_to ", map[string]interface{}{"created_at_from": "2021-01-01", "created_at_to": "2021-01-31 10:00:00"}},
+		{"number", "", []int{1, 9}, "AND `created_at` >= :created_at_from AND `created_at` <= :created_at_to ", map[string]interface{}{"created_at_from": 1, "created_at_to": 9}},
+		{"date in timezone", "Asia/Jakarta", "2021-01-01,2021-01-31", both, map[string]interface{}{
+			"created_at_from": time.Date(2020, 12, 31, 17, 0, 0, 0, time.UTC),
+			"created_at_to":   time.Date(2021, 1, 31, 17, 0, 0, 0, time.UTC),
+		}},
+		{"date in UTC", "UTC", ",2021-01-31", "AND `created_at` < :created_at_to ", map[string]interface{}{"created_at_to": time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)}},
+		{"datetime in timezone", "Asia/Jakarta", ",2021-01-31T10:00:00", "AND `created_at` <= :created_at_to ", map[string]interface{}{"created_at_to": time.Date(2021, 1, 31, 3, 0, 0, 0, time.UTC)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{Condition: "between", Param: "created_at", Timezone: tt.tz}
+			condition, values, err := QueryCondition(cfg, map[string]interface{}{"created_at": tt.value}, "", map[string]interface{}{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if condition != tt.condition {
+				t.Errorf("condition = %q, want %q", condition, tt.condition)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("values = %v, want %v", values, tt.values)
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package librabbitmq
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/helloferdie/stdgo/libmetrics"
+	"github.com/helloferdie/stdgo/librequestid"
 	"github.com/helloferdie/stdgo/libstring"
 	"github.com/helloferdie/stdgo/logger"
 
@@ -25,6 +30,7 @@ type Queue struct {
 // Resource -
 type Resource struct {
 	Queue        Queue
+	mu           sync.RWMutex // Guard errorChannel, connection, channel and closed
 	errorChannel chan *amqp.Error
 	connection   *amqp.Connection
 	channel      *amqp.Channel
@@ -58,21 +64,27 @@ func (r *Resource) Connect(log bool) error {
 		}
 		return err
 	}
+	errorChannel := make(chan *amqp.Error)
+	conn.NotifyClose(errorChannel)
+	r.mu.Lock()
 	r.connection = conn
-	r.errorChannel = make(chan *amqp.Error)
-	r.connection.NotifyClose(r.errorChannel)
+	r.closed = false
+	r.errorChannel = errorChannel
+	r.mu.Unlock()
 
-	ch, err := r.connection.Channel()
+	ch, err := conn.Channel()
 	if err != nil {
-		r.connection.Close()
+		conn.Close()
 		if log {
 			logger.MakeLogEntry(nil, false).Errorf("%s (%v)", "Failed to open a channel", err)
 		}
 		return err
 	}
+	r.mu.Lock()
 	r.channel = ch
+	r.mu.Unlock()
 
-	_, err = r.channel.QueueDeclare(
+	_, err = ch.QueueDeclare(
 		r.Queue.Name,             // name
 		r.Queue.Durable,          // durable
 		r.Queue.DeleteWhenUnused, // delete when unused
@@ -81,8 +93,8 @@ func (r *Resource) Connect(log bool) error {
 		nil,                      // arguments
 	)
 	if err != nil {
-		r.channel.Close()
-		r.connection.Close()
+		ch.Close()
+		conn.Close()
 		if log {
 			logger.MakeLogEntry(nil, false).Errorf("%s (%v)", "Failed to declare a queue", err)
 		}
@@ -103,8 +115,14 @@ func (r *Resource) Reconnect() {
 	}
 	logger.PrintLogEntry("info", fmt.Sprintf("Consumer set to auto reconnect in %v seconds", retry), false)
 	for {
-		err := <-r.errorChannel
-		if !r.closed {
+		r.mu.RLock()
+		errorChannel := r.errorChannel
+		r.mu.RUnlock()
+		err := <-errorChannel
+		r.mu.RLock()
+		closed := r.closed
+		r.mu.RUnlock()
+		if !closed {
 			logger.PrintLogEntry("info", fmt.Sprintf("Connection lost %v", err), false)
 			logger.PrintLogEntry("info", "Attempt to reconnect in "+retryEnv+" seconds", false)
 			time.Sleep(time.Second * time.Duration(retry))
@@ -116,17 +134,60 @@ func (r *Resource) Reconnect() {
 	}
 }
 
+// IsConnected - check connection is open
+func (r *Resource) IsConnected() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !r.closed && r.connection != nil && !r.connection.IsClosed() && r.channel != nil
+}
+
+// IsIdle - check resource is not connected or closed after use, e.g. publisher resource closed after publish
+func (r *Resource) IsIdle() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.closed || r.connection == nil
+}
+
+// Ping - check rabbitmq server is reachable, dial and handshake give up on ctx deadline or cancel
+func Ping(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := amqp.DialConfig(os.Getenv("amqp_connection"), amqp.Config{
+		Locale: "en_US",
+		Dial: func(network, addr string) (net.Conn, error) {
+			c, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if deadline, ok := ctx.Deadline(); ok {
+				// Cleared by amqp after handshake
+				c.SetDeadline(deadline)
+			}
+			return c, nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
 // Close - close connection
 func (r *Resource) Close() {
 	//	log.Println("Closing connection")
+	r.mu.Lock()
 	r.closed = true
-	r.channel.Close()
-	r.connection.Close()
+	ch, conn := r.channel, r.connection
+	r.mu.Unlock()
+	ch.Close()
+	conn.Close()
 }
 
 // registerConsumer - register consumer
 func (r *Resource) registerConsumer() (<-chan amqp.Delivery, error) {
-	msgs, err := r.channel.Consume(
+	r.mu.RLock()
+	ch := r.channel
+	r.mu.RUnlock()
+	msgs, err := ch.Consume(
 		r.Queue.Name, // queue
 		"",           // consumer
 		false,        // auto-ack
@@ -182,16 +243,33 @@ func (r *Resource) recoverConsumers() {
 	logger.PrintLogEntry("info", "Recovering consumer success", false)
 }
 
+var metricPublished = libmetrics.NewCounter("rabbitmq_published_total", "Total published messages", "queue", "result")
+
 // Publish - publish message
 func Publish(r *Resource, appID string, payload map[string]interface{}) error {
+	return PublishContext(context.Background(), r, appID, payload)
+}
+
+// PublishContext - publish message with request ID of ctx in message header
+func PublishContext(ctx context.Context, r *Resource, appID string, payload map[string]interface{}) error {
 	err := r.Connect(true)
 	if err != nil {
+		metricPublished.Inc(r.Queue.Name, "error")
 		return err
 	}
 
 	nUUID := uuid.New()
 	msgID := nUUID.String()
-	err = r.channel.Publish(
+	headers := amqp.Table{
+		"x-retry-count": 0,
+	}
+	if id := librequestid.FromContext(ctx); id != "" {
+		headers[librequestid.AMQPHeader] = id
+	}
+	r.mu.RLock()
+	ch := r.channel
+	r.mu.RUnlock()
+	err = ch.Publish(
 		"",           // exchange
 		r.Queue.Name, // routing key
 		false,        // mandatory
@@ -202,16 +280,17 @@ func Publish(r *Resource, appID string, payload map[string]interface{}) error {
 			Body:         []byte(libstring.JSONEncode(payload)),
 			MessageId:    msgID,
 			AppId:        appID,
-			Headers: amqp.Table{
-				"x-retry-count": 0,
-			},
+			Headers:      headers,
 		})
 	if err != nil {
 		err = fmt.Errorf("%s", "Failed to publish a message")
 		logger.MakeLogEntry(nil, false).Errorf("%v", err)
+		metricPublished.Inc(r.Queue.Name, "error")
+	} else {
+		metricPublished.Inc(r.Queue.Name, "success")
 	}
 	r.Close()
-	logger.PrintLogEntry("info", "Message successfully publish "+appID+" "+msgID, false)
+	logger.PrintLogEntryContext(ctx, "info", "Message successfully publish "+appID+" "+msgID, false)
 	return err
 }
 
@@ -241,9 +320,7 @@ func (r *Resource) Requeue(d amqp.Delivery) error {
 				Body:         d.Body,
 				MessageId:    d.MessageId,
 				AppId:        d.AppId,
-				Headers: amqp.Table{
-					"x-retry-count": retry,
-				},
+				Headers:      requeueHeaders(d, retry),
 			})
 		tmp.Close()
 		return err
@@ -253,6 +330,32 @@ func (r *Resource) Requeue(d amqp.Delivery) error {
 	return nil
 }
 
+// requeueHeaders - Copy request ID of delivery to requeue header
+func requeueHeaders(d amqp.Delivery, retry int) amqp.Table {
+	headers := amqp.Table{
+		"x-retry-count": retry,
+	}
+	if id := RequestID(d); id != "" {
+		headers[librequestid.AMQPHeader] = id
+	}
+	return headers
+}
+
+// RequestID - Return request ID of delivery, empty if not set
+func RequestID(d amqp.Delivery) string {
+	id, _ := d.Headers[librequestid.AMQPHeader].(string)
+	return id
+}
+
+// DeliveryContext - Return context carrying request ID of delivery for consumer logging and publishing
+func DeliveryContext(d amqp.Delivery) context.Context {
+	ctx := context.Background()
+	if id := RequestID(d); id != "" {
+		ctx = librequestid.NewContext(ctx, id)
+	}
+	return ctx
+}
+
 // Dump - dump message to json file
 func (r *Resource) Dump(d amqp.Delivery) {
 	dir := os.Getenv("dir_dump") + "/" + d.AppId
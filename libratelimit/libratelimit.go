@@ -0,0 +1,210 @@
+package libratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// State - Limiter state of a key kept in store
+type State struct {
+	Tokens      float64   `json:"tokens"`       // Token bucket remaining tokens
+	Last        time.Time `json:"last"`         // Token bucket last refill
+	WindowStart time.Time `json:"window_start"` // Sliding window start of current window
+	Current     int64     `json:"current"`      // Sliding window hits in current window
+	Previous    int64     `json:"previous"`     // Sliding window hits in previous window
+}
+
+// Result - Result of a hit
+type Result struct {
+	Allowed    bool
+	Limit      int64
+	Remaining  int64
+	Reset      time.Duration // Until limit is fully available again
+	RetryAfter time.Duration // Until next hit is allowed, 0 when allowed
+}
+
+// Algorithm - Rate limiting algorithm applied to state of a key
+type Algorithm interface {
+	Take(s *State, now time.Time) *Result
+	TTL() time.Duration // How long unused state must be kept
+}
+
+// Limiter - Rate limiter of algorithm with state in store
+type Limiter struct {
+	Algorithm Algorithm
+	Store     Store
+	Prefix    string // Key prefix to share store between limiters
+}
+
+// NewLimiter - Create limiter, default in-memory store
+func NewLimiter(a Algorithm, store Store) *Limiter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Limiter{Algorithm: a, Store: store}
+}
+
+// NewTokenBucket - Create token bucket limiter refilling limit tokens every period up to burst, burst default limit
+func NewTokenBucket(limit int64, period time.Duration, burst int64, store Store) *Limiter {
+	return NewLimiter(&TokenBucket{Limit: limit, Period: period, Burst: burst}, store)
+}
+
+// NewSlidingWindow - Create sliding window limiter allowing limit hits per window
+func NewSlidingWindow(limit int64, window time.Duration, store Store) *Limiter {
+	return NewLimiter(&SlidingWindow{Limit: limit, Window: window}, store)
+}
+
+// Validate - Check limiter has store and valid algorithm
+func (l *Limiter) Validate() error {
+	if l == nil || l.Algorithm == nil {
+		return fmt.Errorf("%s", "rate limit algorithm is required")
+	}
+	if l.Store == nil {
+		return fmt.Errorf("%s", "rate limit store is required")
+	}
+	if v, ok := l.Algorithm.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// Allow - Record a hit of key and return result
+func (l *Limiter) Allow(key string) (*Result, error) {
+	var res *Result
+	err := l.Store.Update(l.Prefix+key, l.Algorithm.TTL(), func(s *State) {
+		res = l.Algorithm.Take(s, time.Now())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// TokenBucket - Allow bursts up to Burst and refill Limit tokens every Period
+type TokenBucket struct {
+	Limit  int64
+	Period time.Duration
+	Burst  int64
+}
+
+// Validate - Check limit and period are positive
+func (b *TokenBucket) Validate() error {
+	if b.Limit <= 0 || b.Period <= 0 || b.Burst < 0 {
+		return fmt.Errorf("token bucket requires positive limit and period, got limit %d period %v burst %d", b.Limit, b.Period, b.Burst)
+	}
+	return nil
+}
+
+// capacity -
+func (b *TokenBucket) capacity() float64 {
+	if b.Burst > 0 {
+		return float64(b.Burst)
+	}
+	return float64(b.Limit)
+}
+
+// rate - Tokens per second
+func (b *TokenBucket) rate() float64 {
+	if b.Limit <= 0 || b.Period <= 0 {
+		return 0
+	}
+	return float64(b.Limit) / b.Period.Seconds()
+}
+
+// Take - Refill bucket and take one token
+func (b *TokenBucket) Take(s *State, now time.Time) *Result {
+	capacity := b.capacity()
+	rate := b.rate()
+	if s.Last.IsZero() {
+		s.Tokens = capacity
+	} else if elapsed := now.Sub(s.Last).Seconds(); elapsed > 0 {
+		s.Tokens = math.Min(capacity, s.Tokens+elapsed*rate)
+	}
+	s.Last = now
+
+	res := &Result{Limit: int64(capacity)}
+	if s.Tokens >= 1 {
+		s.Tokens--
+		res.Allowed = true
+	} else if rate > 0 {
+		res.RetryAfter = secondsToDuration((1 - s.Tokens) / rate)
+	} else {
+		res.RetryAfter = b.Period
+	}
+	res.Remaining = int64(math.Floor(s.Tokens))
+	if rate > 0 {
+		res.Reset = secondsToDuration((capacity - s.Tokens) / rate)
+	}
+	return res
+}
+
+// TTL - Time for empty bucket to refill
+func (b *TokenBucket) TTL() time.Duration {
+	if rate := b.rate(); rate > 0 {
+		return secondsToDuration(b.capacity()/rate) + time.Second
+	}
+	return b.Period
+}
+
+// SlidingWindow - Allow Limit hits in any Window, weighting previous window hits by overlap
+type SlidingWindow struct {
+	Limit  int64
+	Window time.Duration
+}
+
+// Validate - Check limit and window are positive
+func (w *SlidingWindow) Validate() error {
+	if w.Limit <= 0 || w.Window <= 0 {
+		return fmt.Errorf("sliding window requires positive limit and window, got limit %d window %v", w.Limit, w.Window)
+	}
+	return nil
+}
+
+// Take - Count hit in sliding window
+func (w *SlidingWindow) Take(s *State, now time.Time) *Result {
+	start := now.Truncate(w.Window)
+	switch {
+	case s.WindowStart.Equal(start):
+	case s.WindowStart.Add(w.Window).Equal(start):
+		s.Previous, s.Current = s.Current, 0
+	default:
+		s.Previous, s.Current = 0, 0
+	}
+	s.WindowStart = start
+
+	// Portion of previous window still inside sliding window
+	weight := 1 - float64(now.Sub(start))/float64(w.Window)
+	count := float64(s.Previous)*weight + float64(s.Current)
+
+	res := &Result{Limit: w.Limit, Reset: start.Add(w.Window).Sub(now)}
+	if count+1 <= float64(w.Limit) {
+		s.Current++
+		count++
+		res.Allowed = true
+	} else if s.Previous > 0 && float64(s.Current) < float64(w.Limit) {
+		// Wait until enough previous hits slide out
+		need := (count + 1 - float64(w.Limit)) / float64(s.Previous)
+		res.RetryAfter = time.Duration(need * float64(w.Window))
+		if res.RetryAfter > res.Reset {
+			res.RetryAfter = res.Reset
+		}
+	} else {
+		res.RetryAfter = res.Reset
+	}
+	res.Remaining = w.Limit - int64(math.Ceil(count))
+	if res.Remaining < 0 {
+		res.Remaining = 0
+	}
+	return res
+}
+
+// TTL - Current and previous window
+func (w *SlidingWindow) TTL() time.Duration {
+	return 2 * w.Window
+}
+
+// secondsToDuration -
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
@@ -0,0 +1,153 @@
+package libratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	base := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	type hit struct {
+		at        time.Duration
+		allowed   bool
+		remaining int64
+	}
+	tests := []struct {
+		name   string
+		bucket TokenBucket
+		hits   []hit
+	}{
+		{
+			name:   "burst then refill",
+			bucket: TokenBucket{Limit: 1, Period: time.Second, Burst: 2},
+			hits: []hit{
+				{0, true, 1},
+				{0, true, 0},
+				{0, false, 0},
+				{time.Second, true, 0},
+				{time.Second, false, 0},
+			},
+		},
+		{
+			name:   "burst default limit",
+			bucket: TokenBucket{Limit: 2, Period: time.Minute},
+			hits: []hit{
+				{0, true, 1},
+				{0, true, 0},
+				{0, false, 0},
+				{30 * time.Second, true, 0},
+			},
+		},
+		{
+			name:   "refill capped at capacity",
+			bucket: TokenBucket{Limit: 1, Period: time.Second},
+			hits: []hit{
+				{0, true, 0},
+				{time.Hour, true, 0},
+				{time.Hour, false, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &State{}
+			for i, h := range tt.hits {
+				res := tt.bucket.Take(s, base.Add(h.at))
+				if res.Allowed != h.allowed || res.Remaining != h.remaining {
+					t.Errorf("hit %d = allowed %v remaining %d, want %v %d", i, res.Allowed, res.Remaining, h.allowed, h.remaining)
+				}
+				if !res.Allowed && res.RetryAfter <= 0 {
+					t.Errorf("hit %d retry after = %v, want positive", i, res.RetryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	base := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	type hit struct {
+		at      time.Duration
+		allowed bool
+	}
+	tests := []struct {
+		name string
+		hits []hit
+	}{
+		{
+			name: "limit in window",
+			hits: []hit{{0, true}, {time.Second, true}, {2 * time.Second, false}},
+		},
+		{
+			name: "previous window weighted",
+			hits: []hit{
+				{0, true},
+				{time.Second, true},
+				// Half of previous window still counted: 2 * 0.5 + 1 = 2
+				{15 * time.Second, true},
+				{15 * time.Second, false},
+				{29 * time.Second, true},
+			},
+		},
+		{
+			name: "reset after two windows",
+			hits: []hit{{0, true}, {0, true}, {0, false}, {20 * time.Second, true}, {20 * time.Second, true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &SlidingWindow{Limit: 2, Window: 10 * time.Second}
+			s := &State{}
+			for i, h := range tt.hits {
+				res := w.Take(s, base.Add(h.at))
+				if res.Allowed != h.allowed {
+					t.Errorf("hit %d allowed = %v, want %v", i, res.Allowed, h.allowed)
+				}
+				if !res.Allowed && (res.RetryAfter <= 0 || res.RetryAfter > res.Reset) {
+					t.Errorf("hit %d retry after = %v reset %v, want positive up to reset", i, res.RetryAfter, res.Reset)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter *Limiter
+		valid   bool
+	}{
+		{"token bucket", NewTokenBucket(10, time.Second, 0, nil), true},
+		{"sliding window", NewSlidingWindow(10, time.Second, nil), true},
+		{"zero period", NewTokenBucket(10, 0, 0, nil), false},
+		{"negative period", NewTokenBucket(10, -time.Second, 0, nil), false},
+		{"zero limit", NewTokenBucket(0, time.Second, 0, nil), false},
+		{"negative burst", NewTokenBucket(10, time.Second, -1, nil), false},
+		{"zero window", NewSlidingWindow(10, 0, nil), false},
+		{"negative window", NewSlidingWindow(10, -time.Second, nil), false},
+		{"nil limiter", nil, false},
+		{"nil store", &Limiter{Algorithm: &SlidingWindow{Limit: 1, Window: time.Second}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limiter.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	l := NewTokenBucket(1, time.Hour, 0, nil)
+	for i, want := range []bool{true, false} {
+		res, err := l.Allow("a")
+		if err != nil || res.Allowed != want {
+			t.Errorf("hit %d = %v %v, want %v", i, res.Allowed, err, want)
+		}
+	}
+	if res, _ := l.Allow("b"); !res.Allowed {
+		t.Error("other key not allowed")
+	}
+}
@@ -0,0 +1,62 @@
+package libratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Store - Limiter state storage, implement to share state between instances (e.g. Redis with WATCH / MULTI)
+type Store interface {
+	// Update - Load state of key, apply fn and save it for ttl, atomically per key
+	Update(key string, ttl time.Duration, fn func(s *State)) error
+}
+
+// MemoryStore - In-memory store of single instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	lastPurge time.Time
+}
+
+// memoryEntry -
+type memoryEntry struct {
+	state  State
+	expire time.Time
+}
+
+// NewMemoryStore - Create in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:   map[string]*memoryEntry{},
+		lastPurge: time.Now(),
+	}
+}
+
+// Update - Update state of key
+func (m *MemoryStore) Update(key string, ttl time.Duration, fn func(s *State)) error {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastPurge) >= time.Minute {
+		m.purge(now)
+	}
+	entry, exist := m.entries[key]
+	if !exist || now.After(entry.expire) {
+		entry = &memoryEntry{}
+		m.entries[key] = entry
+	}
+	fn(&entry.state)
+	entry.expire = now.Add(ttl)
+	return nil
+}
+
+// purge - Remove expired state
+func (m *MemoryStore) purge(now time.Time) {
+	for key, entry := range m.entries {
+		if now.After(entry.expire) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastPurge = now
+}
@@ -0,0 +1,200 @@
+package librealip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Forwarding header written by trusted proxy
+const (
+	HeaderXForwardedFor = "X-Forwarded-For"
+	HeaderForwarded     = "Forwarded"
+	HeaderXRealIP       = "X-Real-Ip"
+)
+
+// Resolver - Resolve client IP of request, forwarding header is only trusted from trusted proxy
+type Resolver struct {
+	Header  string // Only header read from trusted proxy, default X-Forwarded-For, other forwarding header may be forged by client
+	trusted []*net.IPNet
+}
+
+// Named range accepted in trusted proxy list
+var namedRanges = map[string][]string{
+	"loopback": {"127.0.0.0/8", "::1/128"},
+	"private":  {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultResolver *Resolver
+)
+
+// NewResolver - Create resolver of trusted proxy CIDR, bare IP, "loopback" or "private"
+func NewResolver(trusted ...string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, t := range trusted {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		list := []string{t}
+		if ranges, exist := namedRanges[strings.ToLower(t)]; exist {
+			list = ranges
+		}
+		for _, cidr := range list {
+			if !strings.Contains(cidr, "/") {
+				if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+					cidr += "/32"
+				} else {
+					cidr += "/128"
+				}
+			}
+			_, n, err := net.ParseCIDR(cidr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %s", t)
+			}
+			r.trusted = append(r.trusted, n)
+		}
+	}
+	return r, nil
+}
+
+// ResolverFromEnv - Create resolver of trusted_proxies comma separated list, default loopback,
+// and trusted_proxy_header X-Forwarded-For (default), Forwarded or X-Real-Ip
+func ResolverFromEnv() (*Resolver, error) {
+	v := os.Getenv("trusted_proxies")
+	if v == "" {
+		v = "loopback"
+	}
+	r, err := NewResolver(strings.Split(v, ",")...)
+	if err != nil {
+		return nil, err
+	}
+	switch h := http.CanonicalHeaderKey(os.Getenv("trusted_proxy_header")); h {
+	case "", HeaderXForwardedFor, HeaderForwarded, HeaderXRealIP:
+		r.Header = h
+	default:
+		return nil, fmt.Errorf("invalid trusted proxy header %s", h)
+	}
+	return r, nil
+}
+
+// Default - Return resolver of RealIP, loaded from env on first use
+func Default() *Resolver {
+	defaultMu.RLock()
+	r := defaultResolver
+	defaultMu.RUnlock()
+	if r != nil {
+		return r
+	}
+
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultResolver == nil {
+		r, err := ResolverFromEnv()
+		if err != nil {
+			// Trust nothing on invalid config
+			r = &Resolver{}
+		}
+		defaultResolver = r
+	}
+	return defaultResolver
+}
+
+// SetDefault - Set resolver of RealIP
+func SetDefault(r *Resolver) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultResolver = r
+}
+
+// RealIP - Resolve client IP of request with default resolver
+func RealIP(req *http.Request) string {
+	return Default().Resolve(req)
+}
+
+// Trusted - Check IP is trusted proxy
+func (r *Resolver) Trusted(ip net.IP) bool {
+	for _, n := range r.trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve - Return bare client IP, walk Forwarded or X-Forwarded-For right to left through trusted hop, X-Real-Ip from trusted peer,
+// only Header of resolver is read
+func (r *Resolver) Resolve(req *http.Request) string {
+	remote := parseIP(req.RemoteAddr)
+	if remote == nil {
+		return stripPort(req.RemoteAddr)
+	}
+	if !r.Trusted(remote) {
+		return remote.String()
+	}
+
+	hops := []string{}
+	switch http.CanonicalHeaderKey(r.Header) {
+	case HeaderXRealIP:
+		if ip := parseIP(req.Header.Get(HeaderXRealIP)); ip != nil {
+			return ip.String()
+		}
+	case HeaderForwarded:
+		hops = forwardedFor(req.Header.Values(HeaderForwarded))
+	default:
+		for _, v := range req.Header.Values(HeaderXForwardedFor) {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+	}
+	if len(hops) > 0 {
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := parseIP(hops[i])
+			if ip == nil {
+				// Unparsable hop, e.g. obfuscated identifier, stop at last known
+				break
+			}
+			client = ip
+			if !r.Trusted(ip) {
+				break
+			}
+		}
+		return client.String()
+	}
+	return remote.String()
+}
+
+// forwardedFor - Return for parameter of every Forwarded element in order (RFC 7239)
+func forwardedFor(values []string) []string {
+	list := []string{}
+	for _, v := range values {
+		for _, element := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "for") {
+					list = append(list, kv[1])
+				}
+			}
+		}
+	}
+	return list
+}
+
+// parseIP - Parse IP with optional quote, bracket and port
+func parseIP(s string) net.IP {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	return net.ParseIP(stripPort(s))
+}
+
+// stripPort - Remove port and IPv6 bracket
+func stripPort(s string) string {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+}
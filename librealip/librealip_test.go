@@ -0,0 +1,84 @@
+package librealip
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	r, err := NewResolver("loopback", "10.0.0.0/8", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		source string // Header of resolver
+		remote string
+		header map[string]string
+		want   string
+	}{
+		{"direct client", "", "203.0.113.5:1234", nil, "203.0.113.5"},
+		{"untrusted peer header ignored", "", "203.0.113.5:1234", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Real-Ip": "2.2.2.2"}, "203.0.113.5"},
+		{"trusted peer", "", "127.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.7"}, "198.51.100.7"},
+		{"walk trusted hops", "", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.7, 192.0.2.1, 10.0.0.2"}, "198.51.100.7"},
+		{"spoofed leftmost hop", "", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.0.0.2"}, "198.51.100.7"},
+		{"every hop trusted", "", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"unparsable hop stop at last known", "", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "unknown, 10.0.0.2"}, "10.0.0.2"},
+		{"spoofed forwarded ignored", "", "10.0.0.1:1234", map[string]string{"Forwarded": "for=6.6.6.6", "X-Forwarded-For": "6.6.6.6, 198.51.100.7"}, "198.51.100.7"},
+		{"spoofed real ip ignored", "", "127.0.0.1:1234", map[string]string{"X-Real-Ip": "6.6.6.6"}, "127.0.0.1"},
+		{"forwarded source", "Forwarded", "10.0.0.1:1234", map[string]string{"Forwarded": `for=198.51.100.7;proto=https, for="[2001:db8::1]:443"`, "X-Forwarded-For": "6.6.6.6"}, "2001:db8::1"},
+		{"spoofed xff of forwarded source", "Forwarded", "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "6.6.6.6"}, "10.0.0.1"},
+		{"real ip source", "X-Real-Ip", "[::1]:1234", map[string]string{"X-Real-Ip": "198.51.100.7", "X-Forwarded-For": "6.6.6.6"}, "198.51.100.7"},
+		{"invalid real ip", "X-Real-Ip", "127.0.0.1:1234", map[string]string{"X-Real-Ip": "x"}, "127.0.0.1"},
+		{"remote without port", "", "203.0.113.5", nil, "203.0.113.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remote
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			r.Header = tt.source
+			if got := r.Resolve(req); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolverInvalid(t *testing.T) {
+	if _, err := NewResolver("10.0.0.0/33"); err == nil {
+		t.Error("NewResolver() accepted invalid CIDR")
+	}
+	if _, err := NewResolver("proxy.local"); err == nil {
+		t.Error("NewResolver() accepted host name")
+	}
+}
+
+func TestResolverFromEnv(t *testing.T) {
+	defer os.Unsetenv("trusted_proxy_header")
+	tests := []struct {
+		header  string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"forwarded", "Forwarded", false},
+		{"x-real-ip", "X-Real-Ip", false},
+		{"X-Client-IP", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			os.Setenv("trusted_proxy_header", tt.header)
+			r, err := ResolverFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolverFromEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && r.Header != tt.want {
+				t.Errorf("Header = %q, want %q", r.Header, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package librequestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+)
+
+// Header - HTTP header carrying request ID
+const Header = "X-Request-ID"
+
+// AMQPHeader - AMQP message header carrying request ID
+const AMQPHeader = "x-request-id"
+
+// EchoKey - Echo context key of request ID
+const EchoKey = "request_id"
+
+// contextKey - Go context key of request ID
+type contextKey struct{}
+
+// Regex for accepted incoming request ID
+var regexRequestID = regexp.MustCompile(`^[A-Za-z0-9\-_.:]{1,128}$`)
+
+// Generate - Generate new request ID
+func Generate() string {
+	return uuid.New().String()
+}
+
+// Valid - Check incoming request ID is safe to log and forward
+func Valid(id string) bool {
+	return regexRequestID.MatchString(id)
+}
+
+// NewContext - Return copy of ctx carrying request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext - Return request ID of ctx, empty if not set
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
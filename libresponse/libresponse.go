@@ -23,9 +23,11 @@ type Default struct {
 
 // Pagination - Default pagination response
 type Pagination struct {
-	Items      []interface{} `json:"items"`
-	TotalItems int64         `json:"total_items"`
-	TotalPages int64         `json:"total_pages"`
+	Items        []interface{} `json:"items"`
+	TotalItems   int64         `json:"total_items"`
+	TotalPages   int64         `json:"total_pages"`
+	Page         int64         `json:"page"`
+	ItemsPerPage int64         `json:"items_per_page"`
 }
 
 // GetDefault -
@@ -74,6 +76,7 @@ type Header struct {
 	Access         string
 	RealIP         string
 	RequestURL     string
+	RequestID      string
 	Claims         jwt.MapClaims
 	UserID         int64
 	IsLogin        bool
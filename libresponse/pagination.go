@@ -0,0 +1,26 @@
+package libresponse
+
+// NewPagination - Build pagination response and compute total pages
+func NewPagination(items []interface{}, totalItems int64, page int64, itemsPerPage int64) *Pagination {
+	if items == nil {
+		items = []interface{}{}
+	}
+	p := &Pagination{
+		Items:        items,
+		TotalItems:   totalItems,
+		Page:         page,
+		ItemsPerPage: itemsPerPage,
+	}
+	if itemsPerPage > 0 {
+		p.TotalPages = (totalItems + itemsPerPage - 1) / itemsPerPage
+	}
+	return p
+}
+
+// SuccessList - Return success list with pagination
+func (res *Default) SuccessList(p *Pagination) {
+	res.Code = 200
+	res.Success = true
+	res.Message = "general.success_list"
+	res.Data = p
+}
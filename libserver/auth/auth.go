@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// ClaimsKey - Echo context key of typed claims
+const ClaimsKey = "auth_claims"
+
+// TokenKey - Echo context key of token, same key as echo JWT middleware for claim.GetJWTClaims
+const TokenKey = "user"
+
+// contextKey - Go context key of typed claims
+type contextKey struct{}
+
+// Config - JWT middleware config
+type Config struct {
+	KeySet     *KeySet
+	Algorithms []string // Allowed algorithm, default HS256, RS256 and ES256
+	Issuer     string   // Required iss when set
+	Audience   []string // Token aud must contain one of them when set
+	ClockSkew  time.Duration
+	Optional   bool // Continue without claims when Authorization header is missing
+	Skipper    middleware.Skipper
+}
+
+// Claims - Registered claims of verified token
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+	KeyID     string
+	Algorithm string
+	Map       jwt.MapClaims // Every claim of token
+}
+
+// Middleware - Verify bearer token and set token and typed claims on context
+func Middleware(cfg Config) echo.MiddlewareFunc {
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			header := c.Request().Header.Get("Authorization")
+			if header == "" && cfg.Optional {
+				return next(c)
+			}
+			tokenValue, ok := BearerToken(header)
+			if !ok {
+				return echo.NewHTTPError(400, "missing or malformed jwt")
+			}
+
+			token, claims, err := cfg.Parse(tokenValue)
+			if err != nil {
+				return &echo.HTTPError{
+					Code:     401,
+					Message:  "invalid or expired jwt",
+					Internal: err,
+				}
+			}
+
+			c.Set(TokenKey, token)
+			c.Set(ClaimsKey, claims)
+			c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), contextKey{}, claims)))
+			return next(c)
+		}
+	}
+}
+
+// BearerToken - Return token of Authorization bearer header
+func BearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
+
+// Parse - Verify token signature with key of its kid and validate claims
+func (cfg *Config) Parse(tokenValue string) (*jwt.Token, *Claims, error) {
+	if cfg.KeySet == nil {
+		return nil, nil, fmt.Errorf("%s", "key set not configured")
+	}
+	algs := cfg.Algorithms
+	if len(algs) == 0 {
+		algs = []string{HS256, RS256, ES256}
+	}
+
+	var key *Key
+	parser := &jwt.Parser{ValidMethods: algs, SkipClaimsValidation: true}
+	token, err := parser.Parse(tokenValue, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		k, exist := cfg.KeySet.Get(kid)
+		if !exist {
+			return nil, fmt.Errorf("unknown key id %s", kid)
+		}
+		// Pin algorithm to key to block algorithm confusion
+		if t.Method.Alg() != k.Algorithm {
+			return nil, fmt.Errorf("algorithm %s not allowed for key %s", t.Method.Alg(), kid)
+		}
+		key = k
+		return k.Key, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !token.Valid {
+		return nil, nil, fmt.Errorf("%s", "token is invalid")
+	}
+
+	mc, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s", "claims is invalid")
+	}
+	claims, err := cfg.validate(mc)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims.KeyID = key.ID
+	claims.Algorithm = key.Algorithm
+	return token, claims, nil
+}
+
+// validate - Validate exp, nbf, iss and aud with clock skew
+func (cfg *Config) validate(mc jwt.MapClaims) (*Claims, error) {
+	claims := &Claims{Map: mc}
+	claims.Issuer, _ = mc["iss"].(string)
+	claims.Subject, _ = mc["sub"].(string)
+	claims.ID, _ = mc["jti"].(string)
+	switch aud := mc["aud"].(type) {
+	case string:
+		claims.Audience = []string{aud}
+	case []interface{}:
+		for _, v := range aud {
+			if s, ok := v.(string); ok {
+				claims.Audience = append(claims.Audience, s)
+			}
+		}
+	}
+
+	var ok bool
+	now := time.Now()
+	if claims.ExpiresAt, ok = numericDate(mc["exp"]); !ok {
+		return nil, fmt.Errorf("%s", "Token has no expiry")
+	}
+	if now.After(claims.ExpiresAt.Add(cfg.ClockSkew)) {
+		// Same message as jwt-go so ErrorHandler report general.error_jwt_expired
+		return nil, fmt.Errorf("%s", "Token is expired")
+	}
+	if mc["nbf"] != nil {
+		if claims.NotBefore, ok = numericDate(mc["nbf"]); !ok || now.Add(cfg.ClockSkew).Before(claims.NotBefore) {
+			return nil, fmt.Errorf("%s", "Token is not valid yet")
+		}
+	}
+	if mc["iat"] != nil {
+		if claims.IssuedAt, ok = numericDate(mc["iat"]); !ok {
+			return nil, fmt.Errorf("%s", "Token has invalid issued at")
+		}
+	}
+	if cfg.Issuer != "" && claims.Issuer != cfg.Issuer {
+		return nil, fmt.Errorf("%s", "Token has invalid issuer")
+	}
+	if len(cfg.Audience) > 0 && !containsAny(claims.Audience, cfg.Audience) {
+		return nil, fmt.Errorf("%s", "Token has invalid audience")
+	}
+	return claims, nil
+}
+
+// GetClaims - Return typed claims set by middleware, nil if not authenticated
+func GetClaims(c echo.Context) *Claims {
+	claims, _ := c.Get(ClaimsKey).(*Claims)
+	return claims
+}
+
+// FromContext - Return typed claims of Go context, nil if not authenticated
+func FromContext(ctx context.Context) *Claims {
+	claims, _ := ctx.Value(contextKey{}).(*Claims)
+	return claims
+}
+
+// numericDate - Convert JSON number of seconds to time
+func numericDate(v interface{}) (time.Time, bool) {
+	f, ok := v.(float64)
+	if !ok || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+// containsAny - Check a contains any of b
+func containsAny(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
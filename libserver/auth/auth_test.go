@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// sign - Sign claims with method, kid and key
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, mc jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, mc)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	s, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestConfigParse(t *testing.T) {
+	ec, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := NewKeySet(
+		NewHMACKey("a", "secret-a"),
+		NewHMACKey("b", "secret-b"),
+		&Key{ID: "ec", Algorithm: ES256, Key: &ec.PublicKey},
+	)
+	now := time.Now()
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "1",
+			"iss": "stdgo",
+			"aud": []interface{}{"api"},
+			"exp": float64(now.Add(time.Minute).Unix()),
+		}
+	}
+	with := func(k string, v interface{}) jwt.MapClaims {
+		mc := valid()
+		if v == nil {
+			delete(mc, k)
+		} else {
+			mc[k] = v
+		}
+		return mc
+	}
+	cfg := Config{KeySet: ks, Issuer: "stdgo", Audience: []string{"api"}, ClockSkew: 30 * time.Second}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid kid a", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), valid()), true},
+		{"valid kid b", sign(t, jwt.SigningMethodHS256, "b", []byte("secret-b"), valid()), true},
+		{"valid ecdsa", sign(t, jwt.SigningMethodES256, "ec", ec, valid()), true},
+		{"key of other kid", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-b"), valid()), false},
+		{"unknown kid", sign(t, jwt.SigningMethodHS256, "c", []byte("secret-a"), valid()), false},
+		{"missing kid of many keys", sign(t, jwt.SigningMethodHS256, "", []byte("secret-a"), valid()), false},
+		{"algorithm not pinned to key", sign(t, jwt.SigningMethodHS384, "a", []byte("secret-a"), valid()), false},
+		{"none algorithm", sign(t, jwt.SigningMethodNone, "a", jwt.UnsafeAllowNoneSignatureType, valid()), false},
+		{"no expiry", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("exp", nil)), false},
+		{"expired", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("exp", float64(now.Add(-time.Minute).Unix()))), false},
+		{"expired within skew", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("exp", float64(now.Add(-10*time.Second).Unix()))), true},
+		{"not valid yet", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("nbf", float64(now.Add(time.Minute).Unix()))), false},
+		{"not valid yet within skew", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("nbf", float64(now.Add(10*time.Second).Unix()))), true},
+		{"wrong issuer", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("iss", "other")), false},
+		{"wrong audience", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("aud", "web")), false},
+		{"audience string", sign(t, jwt.SigningMethodHS256, "a", []byte("secret-a"), with("aud", "api")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, claims, err := cfg.Parse(tt.token)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Parse() error = %v, want valid %v", err, tt.valid)
+			}
+			if tt.valid && claims.Subject != "1" {
+				t.Errorf("Subject = %q, want 1", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestKeySetGet(t *testing.T) {
+	tests := []struct {
+		name  string
+		ks    *KeySet
+		kid   string
+		want  string
+		exist bool
+	}{
+		{"kid", NewKeySet(NewHMACKey("a", "x"), NewHMACKey("b", "y")), "b", "b", true},
+		{"unknown kid", NewKeySet(NewHMACKey("a", "x")), "b", "", false},
+		{"empty kid of single key", NewKeySet(NewHMACKey("a", "x")), "", "a", true},
+		{"empty kid of many keys", NewKeySet(NewHMACKey("a", "x"), NewHMACKey("b", "y")), "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, exist := tt.ks.Get(tt.kid)
+			if exist != tt.exist || (exist && k.ID != tt.want) {
+				t.Errorf("Get(%q) = %v, %v, want %q, %v", tt.kid, k, exist, tt.want, tt.exist)
+			}
+		})
+	}
+}
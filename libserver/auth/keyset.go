@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/helloferdie/stdgo/logger"
+)
+
+// Supported algorithm
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	ES256 = "ES256"
+)
+
+// Key - Verification key of a key ID
+type Key struct {
+	ID        string
+	Algorithm string      // HS256, RS256 or ES256
+	Key       interface{} // []byte, *rsa.PublicKey or *ecdsa.PublicKey
+}
+
+// KeyLoader - Load every key of key set
+type KeyLoader func() ([]*Key, error)
+
+// KeySet - Key ID indexed verification keys, reloadable from loader
+type KeySet struct {
+	mu     sync.RWMutex
+	keys   map[string]*Key
+	loader KeyLoader
+}
+
+// NewKeySet - Create static key set
+func NewKeySet(keys ...*Key) *KeySet {
+	ks := &KeySet{}
+	ks.set(keys)
+	return ks
+}
+
+// NewKeySetLoader - Create key set and load keys from loader
+func NewKeySetLoader(loader KeyLoader) (*KeySet, error) {
+	ks := &KeySet{loader: loader}
+	if err := ks.Reload(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// NewHMACKey - Create HS256 key from secret
+func NewHMACKey(kid string, secret string) *Key {
+	return &Key{ID: kid, Algorithm: HS256, Key: []byte(secret)}
+}
+
+// Reload - Load keys from loader and swap key set, keep current keys on error
+func (ks *KeySet) Reload() error {
+	if ks.loader == nil {
+		return nil
+	}
+	keys, err := ks.loader()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("%s", "key set is empty")
+	}
+	ks.set(keys)
+	return nil
+}
+
+// Watch - Reload key set every interval until ctx done
+func (ks *KeySet) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ks.Reload(); err != nil {
+				logger.MakeLogEntry(nil, false).Errorf("Fail to reload JWT key set %v", err)
+			}
+		}
+	}
+}
+
+// Get - Return key of key ID, the only key when kid is empty and key set has one key
+func (ks *KeySet) Get(kid string) (*Key, bool) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if kid == "" && len(ks.keys) == 1 {
+		for _, k := range ks.keys {
+			return k, true
+		}
+	}
+	k, exist := ks.keys[kid]
+	return k, exist
+}
+
+// set -
+func (ks *KeySet) set(keys []*Key) {
+	m := map[string]*Key{}
+	for _, k := range keys {
+		m[k.ID] = k
+	}
+	ks.mu.Lock()
+	ks.keys = m
+	ks.mu.Unlock()
+}
+
+// PEMFiles - Load public key or certificate PEM file per key ID
+func PEMFiles(files map[string]string) KeyLoader {
+	return func() ([]*Key, error) {
+		keys := []*Key{}
+		for kid, file := range files {
+			b, err := ioutil.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			k, err := ParsePEM(kid, b)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %v", kid, err)
+			}
+			keys = append(keys, k)
+		}
+		return keys, nil
+	}
+}
+
+// ParsePEM - Parse RSA or P-256 EC public key, PKIX or PKCS1 public key or certificate
+func ParsePEM(kid string, b []byte) (*Key, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("%s", "no PEM block found")
+	}
+
+	var pub interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return publicKey(kid, pub)
+}
+
+// publicKey - Create key with algorithm of public key type
+func publicKey(kid string, pub interface{}) (*Key, error) {
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: kid, Algorithm: RS256, Key: k}, nil
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s", "unsupported EC curve, P-256 required")
+		}
+		return &Key{ID: kid, Algorithm: ES256, Key: k}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", pub)
+}
+
+// jwk - JSON web key
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// JWKSFile - Load keys from local JWKS document
+func JWKSFile(file string) KeyLoader {
+	return func() ([]*Key, error) {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return ParseJWKS(b)
+	}
+}
+
+// ParseJWKS - Parse RSA, P-256 EC and oct keys of JWKS document, skip key not for signature
+func ParseJWKS(b []byte) ([]*Key, error) {
+	doc := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	keys := []*Key{}
+	for _, j := range doc.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.key()
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", j.Kid, err)
+		}
+		if j.Alg != "" && j.Alg != k.Algorithm {
+			return nil, fmt.Errorf("key %s: algorithm %s does not match key type", j.Kid, j.Alg)
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// key - Convert JSON web key to key
+func (j *jwk) key() (*Key, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return publicKey(j.Kid, &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case "EC":
+		if j.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported EC curve %s", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return publicKey(j.Kid, &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(j.K)
+		if err != nil {
+			return nil, err
+		}
+		return &Key{ID: j.Kid, Algorithm: HS256, Key: secret}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", j.Kty)
+}
+
+// decodeBigInt - Decode base64url unsigned big integer
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
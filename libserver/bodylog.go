@@ -0,0 +1,280 @@
+package libserver
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// redacted - Replacement of redacted value
+const redacted = "[REDACTED]"
+
+// DefaultRedactHeaders - Header redacted by default
+var DefaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Secret", "X-Signature"}
+
+// DefaultRedactFields - JSON and form field redacted by default at any depth
+var DefaultRedactFields = []string{"password", "new_password", "old_password", "password_confirmation", "client_secret", "secret", "access_token", "refresh_token", "token"}
+
+// BodyLogConfig - Body logging middleware config
+type BodyLogConfig struct {
+	MaxBodySize   int      // Captured bytes of each body, default 4096
+	RedactHeaders []string // Default DefaultRedactHeaders
+	RedactFields  []string // Field name at any depth or dot path from root, "*" match any key or index, e.g. "data.*.token"; default DefaultRedactFields
+	SampleRate    float64  // Portion of successful request logged, 0 to 1
+	ErrorStatus   int      // Always log response status at least, default 400
+	Skipper       middleware.Skipper
+}
+
+// BodyLog - Log request and response with capped and redacted bodies, latency, status and bytes to structured logger
+func BodyLog(cfg BodyLogConfig) echo.MiddlewareFunc {
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 4096
+	}
+	if cfg.RedactHeaders == nil {
+		cfg.RedactHeaders = DefaultRedactHeaders
+	}
+	if cfg.RedactFields == nil {
+		cfg.RedactFields = DefaultRedactFields
+	}
+	if cfg.ErrorStatus <= 0 {
+		cfg.ErrorStatus = 400
+	}
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+	r := newRedactor(cfg.RedactHeaders, cfg.RedactFields)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			reqBody, reqTruncated := captureBody(req, cfg.MaxBodySize)
+			resCapture := &bodyCapture{ResponseWriter: c.Response().Writer, max: cfg.MaxBodySize}
+			c.Response().Writer = resCapture
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				// Run error handler to log final status and body, error is still returned to outer middleware
+				c.Error(err)
+			}
+			latency := time.Since(start)
+
+			status := c.Response().Status
+			if status < cfg.ErrorStatus && (cfg.SampleRate <= 0 || rand.Float64() >= cfg.SampleRate) {
+				return err
+			}
+
+			res := c.Response()
+			logger.MakeLogEntry(c, false).WithFields(map[string]interface{}{
+				"status":           status,
+				"latency_ms":       float64(latency.Microseconds()) / 1000,
+				"bytes_in":         req.ContentLength,
+				"bytes_out":        res.Size,
+				"request_headers":  r.headers(req.Header),
+				"request_body":     r.body(reqBody, reqTruncated, req.Header.Get(echo.HeaderContentType)),
+				"response_headers": r.headers(res.Header()),
+				"response_body":    r.body(resCapture.buf.Bytes(), resCapture.truncated, res.Header().Get(echo.HeaderContentType)),
+			}).Info("request completed")
+			return err
+		}
+	}
+}
+
+// captureBody - Read up to max bytes of request body and restore body for handler
+func captureBody(req *http.Request, max int) ([]byte, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, false
+	}
+	b, _ := ioutil.ReadAll(io.LimitReader(req.Body, int64(max)+1))
+	req.Body = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(b), req.Body), Closer: req.Body}
+	if len(b) > max {
+		return b[:max], true
+	}
+	return b, false
+}
+
+// multiReadCloser -
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyCapture - Response writer keeping first max bytes of body
+type bodyCapture struct {
+	http.ResponseWriter
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write -
+func (w *bodyCapture) Write(b []byte) (int, error) {
+	if left := w.max - w.buf.Len(); left > 0 {
+		if len(b) > left {
+			w.buf.Write(b[:left])
+			w.truncated = true
+		} else {
+			w.buf.Write(b)
+		}
+	} else if len(b) > 0 {
+		w.truncated = true
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush -
+func (w *bodyCapture) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack -
+func (w *bodyCapture) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, fmt.Errorf("%s", "response writer does not support hijack")
+}
+
+// redactor - Redact header and body field
+type redactor struct {
+	headerSet map[string]bool
+	fieldSet  map[string]bool // Field name at any depth
+	paths     [][]string      // Dot path from root
+}
+
+// newRedactor -
+func newRedactor(headers []string, fields []string) *redactor {
+	r := &redactor{headerSet: map[string]bool{}, fieldSet: map[string]bool{}}
+	for _, h := range headers {
+		r.headerSet[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, f := range fields {
+		if strings.Contains(f, ".") {
+			r.paths = append(r.paths, strings.Split(f, "."))
+		} else {
+			r.fieldSet[strings.ToLower(f)] = true
+		}
+	}
+	return r
+}
+
+// headers - Return header with redacted value
+func (r *redactor) headers(h http.Header) map[string]string {
+	m := map[string]string{}
+	for k, v := range h {
+		if r.headerSet[http.CanonicalHeaderKey(k)] {
+			m[k] = redacted
+		} else {
+			m[k] = strings.Join(v, ", ")
+		}
+	}
+	return m
+}
+
+// body - Return loggable body, JSON and form body redacted, body that cannot be redacted is omitted
+func (r *redactor) body(b []byte, truncated bool, contentType string) interface{} {
+	if len(b) == 0 {
+		return nil
+	}
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEApplicationJSON):
+		var v interface{}
+		if err := json.Unmarshal(b, &v); err != nil {
+			// Truncated JSON may hide field to redact
+			return fmt.Sprintf("[unparsable JSON %d bytes]", len(b))
+		}
+		return r.value(v, []string{})
+	case strings.HasPrefix(contentType, echo.MIMEApplicationForm):
+		if truncated {
+			return fmt.Sprintf("[truncated form %d bytes]", len(b))
+		}
+		q, err := url.ParseQuery(string(b))
+		if err != nil {
+			return fmt.Sprintf("[unparsable form %d bytes]", len(b))
+		}
+		for k := range q {
+			if r.match([]string{k}) {
+				q[k] = []string{redacted}
+			}
+		}
+		return q.Encode()
+	case strings.HasPrefix(contentType, "text/"):
+		if truncated {
+			return string(b) + "...[truncated]"
+		}
+		return string(b)
+	}
+	return fmt.Sprintf("[%s %d bytes]", contentType, len(b))
+}
+
+// value - Redact JSON value recursively
+func (r *redactor) value(v interface{}, path []string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			p := append(append([]string{}, path...), k)
+			if r.match(p) {
+				t[k] = redacted
+			} else {
+				t[k] = r.value(child, p)
+			}
+		}
+	case []interface{}:
+		for i, child := range t {
+			p := append(append([]string{}, path...), "*")
+			if r.match(p) {
+				t[i] = redacted
+			} else {
+				t[i] = r.value(child, p)
+			}
+		}
+	}
+	return v
+}
+
+// match - Check field of path is redacted
+func (r *redactor) match(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	if r.fieldSet[strings.ToLower(path[len(path)-1])] {
+		return true
+	}
+	for _, p := range r.paths {
+		if len(p) != len(path) {
+			continue
+		}
+		matched := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
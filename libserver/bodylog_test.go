@@ -0,0 +1,141 @@
+package libserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// secret - Value that must never reach log
+const secret = "hunter2"
+
+func TestRedactorBody(t *testing.T) {
+	r := newRedactor(DefaultRedactHeaders, append([]string{"data.*.pin", "meta.key"}, DefaultRedactFields...))
+	tests := []struct {
+		name        string
+		body        string
+		truncated   bool
+		contentType string
+		want        string
+	}{
+		{"json field", `{"user":"a","password":"hunter2"}`, false, "application/json", `{"password":"[REDACTED]","user":"a"}`},
+		{"json nested any depth", `{"auth":{"Token":"hunter2"},"list":[{"secret":"hunter2"}]}`, false, "application/json; charset=utf-8", `{"auth":{"Token":"[REDACTED]"},"list":[{"secret":"[REDACTED]"}]}`},
+		{"json star path of array", `{"data":[{"pin":"hunter2","id":1}],"pin":"visible"}`, false, "application/json", `{"data":[{"id":1,"pin":"[REDACTED]"}],"pin":"visible"}`},
+		{"json star path of object", `{"data":{"a":{"pin":"hunter2"}}}`, false, "application/json", `{"data":{"a":{"pin":"[REDACTED]"}}}`},
+		{"json dot path from root only", `{"meta":{"key":"hunter2"},"other":{"key":"visible"}}`, false, "application/json", `{"meta":{"key":"[REDACTED]"},"other":{"key":"visible"}}`},
+		{"json array of secret", `{"token":["hunter2"]}`, false, "application/json", `{"token":"[REDACTED]"}`},
+		{"truncated json omitted", `{"user":"a","password":"hunt`, true, "application/json", `"[unparsable JSON 28 bytes]"`},
+		{"form field", "user=a&password=hunter2", false, "application/x-www-form-urlencoded", `"password=%5BREDACTED%5D\u0026user=a"`},
+		{"truncated form omitted", "user=a&password=hun", true, "application/x-www-form-urlencoded", `"[truncated form 19 bytes]"`},
+		{"text", "hello", false, "text/plain", `"hello"`},
+		{"truncated text", "hel", true, "text/plain", `"hel...[truncated]"`},
+		{"binary omitted", "hunter2", false, "application/octet-stream", `"[application/octet-stream 7 bytes]"`},
+		{"empty", "", false, "application/json", `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := json.Marshal(r.body([]byte(tt.body), tt.truncated, tt.contentType))
+			if string(b) != tt.want {
+				t.Errorf("body() = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorHeaders(t *testing.T) {
+	r := newRedactor([]string{"authorization", "X-Api-Key"}, nil)
+	h := http.Header{}
+	h.Set("Authorization", "Bearer "+secret)
+	h.Set("X-Api-Key", secret)
+	h.Add("Accept", "a")
+	h.Add("Accept", "b")
+	got := r.headers(h)
+	want := map[string]string{"Authorization": redacted, "X-Api-Key": redacted, "Accept": "a, b"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("header %s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestBodyLog(t *testing.T) {
+	hook := test.NewLocal(logrus.New())
+	logger.AddHook(hook)
+
+	errHandler := echo.NewHTTPError(422, "general.error_validation")
+	tests := []struct {
+		name    string
+		cfg     BodyLogConfig
+		err     error
+		status  int
+		logged  bool
+		wantErr bool
+	}{
+		{"success not sampled", BodyLogConfig{}, nil, 200, false, false},
+		{"success sampled", BodyLogConfig{SampleRate: 1}, nil, 200, true, false},
+		{"handler error logged and returned", BodyLogConfig{}, errHandler, 422, true, true},
+		{"handler error below error status", BodyLogConfig{ErrorStatus: 500}, errHandler, 422, false, true},
+		{"internal error", BodyLogConfig{}, errors.New("boom " + secret), 500, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook.Reset()
+			e := echo.New()
+			e.HTTPErrorHandler = ErrorHandler
+			var outerErr error
+			outer := func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					outerErr = next(c)
+					return outerErr
+				}
+			}
+			e.POST("/login", func(c echo.Context) error {
+				if tt.err != nil {
+					return tt.err
+				}
+				return c.JSON(200, map[string]interface{}{"access_token": secret, "user": "a"})
+			}, outer, BodyLog(tt.cfg))
+
+			req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"user":"a","password":"`+secret+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+secret)
+			req.Header.Set("Cookie", "session="+secret)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if (outerErr != nil) != tt.wantErr {
+				t.Errorf("outer middleware error = %v, wantErr %v", outerErr, tt.wantErr)
+			}
+			entries := []*logrus.Entry{}
+			for _, entry := range hook.AllEntries() {
+				if entry.Message == "request completed" {
+					entries = append(entries, entry)
+				}
+			}
+			if (len(entries) > 0) != tt.logged {
+				t.Fatalf("logged = %v, want %v", len(entries) > 0, tt.logged)
+			}
+			for _, entry := range entries {
+				b, _ := json.Marshal(entry.Data)
+				if strings.Contains(string(b), secret) {
+					t.Errorf("secret in log entry %s", b)
+				}
+				if entry.Data["status"] != tt.status {
+					t.Errorf("logged status = %v, want %d", entry.Data["status"], tt.status)
+				}
+			}
+		})
+	}
+}
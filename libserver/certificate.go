@@ -0,0 +1,184 @@
+package libserver
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/helloferdie/stdgo/logger"
+)
+
+// TLS profile name
+const (
+	TLSProfileModern       = "modern"       // TLS 1.3 only
+	TLSProfileIntermediate = "intermediate" // TLS 1.2 with forward secrecy AEAD cipher suites and TLS 1.3
+)
+
+// TLSProfile - Return TLS config of profile without certificate, default intermediate
+func TLSProfile(name string) *tls.Config {
+	cfg := new(tls.Config)
+	cfg.CurvePreferences = []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384}
+	if name == TLSProfileModern {
+		// TLS 1.3 cipher suites are not configurable
+		cfg.MinVersion = tls.VersionTLS13
+		return cfg
+	}
+
+	cfg.MinVersion = tls.VersionTLS12
+	cfg.CipherSuites = []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	}
+	return cfg
+}
+
+// CertManager - Serve certificate and key file through GetCertificate and reload them on change
+type CertManager struct {
+	CertFile      string
+	KeyFile       string
+	ExpiryWarning time.Duration // Log warning when certificate expire within, default 30 days
+
+	cert    atomic.Value // *tls.Certificate
+	mu      sync.Mutex
+	modTime time.Time
+	expiry  time.Time
+	warned  time.Time
+}
+
+// NewCertManager - Create certificate manager and load certificate and key file
+func NewCertManager(certFile string, keyFile string) (*CertManager, error) {
+	m := &CertManager{
+		CertFile:      certFile,
+		KeyFile:       keyFile,
+		ExpiryWarning: 30 * 24 * time.Hour,
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload - Load certificate and key file and swap current certificate
+func (m *CertManager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.reload()
+}
+
+// reload -
+func (m *CertManager) reload() error {
+	modTime, err := m.lastModified()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(m.CertFile, m.KeyFile)
+	if err != nil {
+		return fmt.Errorf("fail to load certificate and key file: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("fail to parse certificate: %v", err)
+	}
+	cert.Leaf = leaf
+
+	m.cert.Store(&cert)
+	m.modTime = modTime
+	m.expiry = leaf.NotAfter
+	m.checkExpiry()
+	return nil
+}
+
+// Watch - Poll certificate and key file every interval and reload on change until ctx done
+func (m *CertManager) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.poll()
+		}
+	}
+}
+
+// poll - Reload certificate when file changed
+func (m *CertManager) poll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	modTime, err := m.lastModified()
+	if err != nil {
+		logger.MakeLogEntry(nil, false).Errorf("Fail to check certificate file %v", err)
+		return
+	}
+	if modTime.Equal(m.modTime) {
+		m.checkExpiry()
+		return
+	}
+	if err := m.reload(); err != nil {
+		// Keep serving current certificate
+		logger.MakeLogEntry(nil, false).Errorf("Fail to reload certificate %v", err)
+		return
+	}
+	logger.MakeLogEntry(nil, false).Infof("Reload certificate %s, expire at %s", m.CertFile, m.expiry.Format(time.RFC3339))
+}
+
+// GetCertificate - Return current certificate, use as tls.Config GetCertificate
+func (m *CertManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, ok := m.cert.Load().(*tls.Certificate)
+	if !ok || cert == nil {
+		return nil, fmt.Errorf("%s", "certificate not loaded")
+	}
+	return cert, nil
+}
+
+// NotAfter - Return expiry time of current certificate
+func (m *CertManager) NotAfter() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expiry
+}
+
+// lastModified - Return latest modification time of certificate and key file
+func (m *CertManager) lastModified() (time.Time, error) {
+	certInfo, err := os.Stat(m.CertFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	keyInfo, err := os.Stat(m.KeyFile)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if keyInfo.ModTime().After(certInfo.ModTime()) {
+		return keyInfo.ModTime(), nil
+	}
+	return certInfo.ModTime(), nil
+}
+
+// checkExpiry - Log warning once a day when certificate is near expiry
+func (m *CertManager) checkExpiry() {
+	left := time.Until(m.expiry)
+	if left > m.ExpiryWarning || time.Since(m.warned) < 24*time.Hour {
+		return
+	}
+	m.warned = time.Now()
+	if left <= 0 {
+		logger.MakeLogEntry(nil, false).Errorf("Certificate %s has expired at %s", m.CertFile, m.expiry.Format(time.RFC3339))
+		return
+	}
+	logger.MakeLogEntry(nil, false).Warnf("Certificate %s expire in %d days at %s", m.CertFile, int(left.Hours()/24), m.expiry.Format(time.RFC3339))
+}
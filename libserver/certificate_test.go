@@ -0,0 +1,233 @@
+package libserver
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// writeCert - Write self signed certificate and key file of common name expire at notAfter with modification time
+func writeCert(t *testing.T, dir string, cn string, notAfter time.Time, modTime time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	writePEM(t, certFile, "CERTIFICATE", der, modTime)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER, modTime)
+	return certFile, keyFile
+}
+
+// writePEM - Write PEM block to file with modification time
+func writePEM(t *testing.T, name string, typ string, b []byte, modTime time.Time) {
+	t.Helper()
+	if err := ioutil.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: b}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(name, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// servedName - Return common name of certificate served by manager
+func servedName(t *testing.T, m *CertManager) string {
+	t.Helper()
+	cert, err := m.GetCertificate(&tls.ClientHelloInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.Leaf.Subject.CommonName
+}
+
+func TestCertManagerReload(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	expiry := now.Add(365 * 24 * time.Hour).Truncate(time.Second)
+	certFile, keyFile := writeCert(t, dir, "a.example.com", expiry, now.Add(-time.Hour))
+	m, err := NewCertManager(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := servedName(t, m); name != "a.example.com" {
+		t.Fatalf("certificate = %s, want a.example.com", name)
+	}
+
+	// Unchanged file is not reloaded
+	m.poll()
+	if name := servedName(t, m); name != "a.example.com" {
+		t.Errorf("certificate of unchanged file = %s, want a.example.com", name)
+	}
+
+	// Changed file is reloaded
+	writeCert(t, dir, "b.example.com", expiry.Add(time.Hour), now)
+	m.poll()
+	if name := servedName(t, m); name != "b.example.com" {
+		t.Errorf("certificate after reload = %s, want b.example.com", name)
+	}
+	if !m.NotAfter().Equal(expiry.Add(time.Hour)) {
+		t.Errorf("NotAfter() = %v, want %v", m.NotAfter(), expiry.Add(time.Hour))
+	}
+
+	// Failed reload keep current certificate
+	writePEM(t, certFile, "CERTIFICATE", []byte("broken"), now.Add(time.Hour))
+	m.poll()
+	if name := servedName(t, m); name != "b.example.com" {
+		t.Errorf("certificate after failed reload = %s, want b.example.com", name)
+	}
+	if err := m.Reload(); err == nil {
+		t.Error("Reload() of broken file without error")
+	}
+	if !m.NotAfter().Equal(expiry.Add(time.Hour)) {
+		t.Errorf("NotAfter() after failed reload = %v, want %v", m.NotAfter(), expiry.Add(time.Hour))
+	}
+
+	// Missing file keep current certificate
+	os.Remove(keyFile)
+	m.poll()
+	if name := servedName(t, m); name != "b.example.com" {
+		t.Errorf("certificate after missing file = %s, want b.example.com", name)
+	}
+}
+
+func TestCertManagerWatch(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	certFile, keyFile := writeCert(t, dir, "a.example.com", now.Add(time.Hour*24*365), now.Add(-time.Hour))
+	m, err := NewCertManager(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go m.Watch(ctx, 10*time.Millisecond)
+
+	writeCert(t, dir, "b.example.com", now.Add(time.Hour*24*365), now)
+	deadline := time.Now().Add(2 * time.Second)
+	for servedName(t, m) != "b.example.com" {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate not reloaded by Watch")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCertManagerExpiryWarning(t *testing.T) {
+	hook := test.NewLocal(logrus.New())
+	logger.AddHook(hook)
+	now := time.Now()
+	tests := []struct {
+		name     string
+		notAfter time.Time
+		level    logrus.Level
+		message  string
+	}{
+		{"valid", now.Add(90 * 24 * time.Hour), 0, ""},
+		{"near expiry", now.Add(10*24*time.Hour + time.Hour), logrus.WarnLevel, "expire in 10 days"},
+		{"expired", now.Add(-time.Minute), logrus.ErrorLevel, "has expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certFile, keyFile := writeCert(t, t.TempDir(), "a.example.com", tt.notAfter, now)
+			hook.Reset()
+			m, err := NewCertManager(certFile, keyFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries := hook.AllEntries()
+			if tt.message == "" {
+				if len(entries) != 0 {
+					t.Errorf("log = %v, want none", entries[0].Message)
+				}
+				return
+			}
+			if len(entries) != 1 || entries[0].Level != tt.level || !strings.Contains(entries[0].Message, tt.message) {
+				t.Fatalf("log = %v, want %s %q", entries, tt.level, tt.message)
+			}
+
+			// Warned once a day
+			hook.Reset()
+			m.poll()
+			if len(hook.AllEntries()) != 0 {
+				t.Errorf("warning repeated within a day: %v", hook.AllEntries()[0].Message)
+			}
+		})
+	}
+}
+
+func TestTLSProfile(t *testing.T) {
+	intermediate := []uint16{
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	}
+	tests := []struct {
+		name       string
+		minVersion uint16
+		ciphers    []uint16
+	}{
+		{TLSProfileModern, tls.VersionTLS13, nil},
+		{TLSProfileIntermediate, tls.VersionTLS12, intermediate},
+		{"", tls.VersionTLS12, intermediate},
+	}
+	insecure := map[uint16]bool{}
+	for _, cs := range tls.InsecureCipherSuites() {
+		insecure[cs.ID] = true
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := TLSProfile(tt.name)
+			if cfg.MinVersion != tt.minVersion {
+				t.Errorf("MinVersion = %x, want %x", cfg.MinVersion, tt.minVersion)
+			}
+			if !reflect.DeepEqual(cfg.CipherSuites, tt.ciphers) {
+				t.Errorf("CipherSuites = %v, want %v", cfg.CipherSuites, tt.ciphers)
+			}
+			for _, id := range cfg.CipherSuites {
+				if insecure[id] {
+					t.Errorf("insecure cipher suite %s", tls.CipherSuiteName(id))
+				}
+			}
+			if len(cfg.Certificates) != 0 || cfg.GetCertificate != nil {
+				t.Error("profile with certificate")
+			}
+		})
+	}
+}
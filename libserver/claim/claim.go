@@ -8,17 +8,28 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// GetJWTClaimsHeader - Get JWT claims from header
+// GetJWTClaimsHeader - Get JWT claims from header, HS256 signed with jwt_secret only, nil when jwt_secret is empty
 func GetJWTClaimsHeader(c echo.Context) jwt.MapClaims {
+	secret := os.Getenv("jwt_secret")
+	if secret == "" {
+		return nil
+	}
 	header := c.Request().Header.Get("Authorization")
-	if header != "" {
-		auth := strings.Split(header, "Bearer ")
-		tokenValue := auth[1]
-		token, err := jwt.Parse(tokenValue, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("jwt_secret")), nil
-		})
-		if err == nil && token.Valid {
-			return token.Claims.(jwt.MapClaims)
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil
+	}
+	tokenValue := strings.TrimSpace(header[len("Bearer "):])
+	if tokenValue == "" {
+		return nil
+	}
+
+	parser := &jwt.Parser{ValidMethods: []string{"HS256"}}
+	token, err := parser.Parse(tokenValue, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	})
+	if err == nil && token.Valid {
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			return claims
 		}
 	}
 	return nil
@@ -26,40 +37,27 @@ func GetJWTClaimsHeader(c echo.Context) jwt.MapClaims {
 
 // GetJWTClaims - Get JWT claims from middleware
 func GetJWTClaims(c echo.Context) jwt.MapClaims {
-	test := c.Get("user")
-	if test == nil {
+	user, ok := c.Get("user").(*jwt.Token)
+	if !ok || user == nil {
 		return GetJWTClaimsHeader(c)
 	}
 
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
+	claims, _ := user.Claims.(jwt.MapClaims)
 	return claims
 }
 
 // GetJWTUserID -
 func GetJWTUserID(c echo.Context) int64 {
-	claims := GetJWTClaims(c)
-	if claims != nil {
-		if len(claims) > 0 {
-			v, ok := claims["user_id"].(float64)
-			if ok {
-				return int64(v)
-			}
-		}
+	if cl := GetClaims(c); cl != nil {
+		return cl.UserID
 	}
 	return 0
 }
 
 // GetJWTAccountID -
 func GetJWTAccountID(c echo.Context) int64 {
-	claims := GetJWTClaims(c)
-	if claims != nil {
-		if len(claims) > 0 {
-			v, ok := claims["account_id"].(float64)
-			if ok {
-				return int64(v)
-			}
-		}
+	if cl := GetClaims(c); cl != nil {
+		return cl.AccountID
 	}
 	return 0
 }
@@ -0,0 +1,55 @@
+package claim
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// sign - Sign claims with method and key
+func sign(t *testing.T, method jwt.SigningMethod, key interface{}, mc jwt.MapClaims) string {
+	s, err := jwt.NewWithClaims(method, mc).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// newContext - Echo context of request with bearer token
+func newContext(token string) echo.Context {
+	req := httptest.NewRequest("GET", "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestGetJWTClaimsHeader(t *testing.T) {
+	mc := jwt.MapClaims{"user_id": 1, "access": "user"}
+	tests := []struct {
+		name   string
+		secret string
+		token  string
+		valid  bool
+	}{
+		{"valid", "secret", sign(t, jwt.SigningMethodHS256, []byte("secret"), mc), true},
+		{"no token", "secret", "", false},
+		{"wrong secret", "secret", sign(t, jwt.SigningMethodHS256, []byte("other"), mc), false},
+		{"other method", "secret", sign(t, jwt.SigningMethodHS512, []byte("secret"), mc), false},
+		{"none method", "secret", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, mc), false},
+		{"empty secret", "", sign(t, jwt.SigningMethodHS256, []byte(""), mc), false},
+	}
+	defer os.Unsetenv("jwt_secret")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("jwt_secret", tt.secret)
+			claims := GetJWTClaimsHeader(newContext(tt.token))
+			if (claims != nil) != tt.valid {
+				t.Errorf("GetJWTClaimsHeader() = %v, want valid %v", claims, tt.valid)
+			}
+		})
+	}
+}
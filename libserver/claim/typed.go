@@ -0,0 +1,141 @@
+package claim
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// Claims - Typed application claims
+type Claims struct {
+	UserID     int64
+	AccountID  int64
+	ClientID   int64
+	ClientUUID string
+	Access     string
+	Scopes     []string
+	SessionID  string
+	Map        jwt.MapClaims // Every claim of token
+}
+
+// NewClaims - Convert map claims to typed claims, missing or mistyped claim is left empty
+func NewClaims(m jwt.MapClaims) *Claims {
+	cl := &Claims{Map: m}
+	cl.UserID = claimInt(m["user_id"])
+	cl.AccountID = claimInt(m["account_id"])
+	cl.ClientID = claimInt(m["client_id"])
+	cl.ClientUUID, _ = m["client_uuid"].(string)
+	cl.Access, _ = m["access"].(string)
+	cl.SessionID, _ = m["session_id"].(string)
+	if cl.SessionID == "" {
+		cl.SessionID, _ = m["sid"].(string)
+	}
+
+	// scope as space separated string (RFC 8693) or scopes as list
+	if s, ok := m["scope"].(string); ok {
+		cl.Scopes = strings.Fields(s)
+	}
+	if list, ok := m["scopes"].([]interface{}); ok {
+		for _, v := range list {
+			if s, ok := v.(string); ok && s != "" {
+				cl.Scopes = append(cl.Scopes, s)
+			}
+		}
+	}
+	return cl
+}
+
+// GetClaims - Get typed claims of request, nil when not authenticated
+func GetClaims(c echo.Context) *Claims {
+	m := GetJWTClaims(c)
+	if len(m) == 0 {
+		return nil
+	}
+	return NewClaims(m)
+}
+
+// IsLogin - Check claims has access level
+func (cl *Claims) IsLogin() bool {
+	return cl != nil && cl.Access != ""
+}
+
+// HasAccess - Check access level is one of levels
+func (cl *Claims) HasAccess(levels ...string) bool {
+	if cl == nil {
+		return false
+	}
+	for _, l := range levels {
+		if cl.Access == l {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope - Check claims has every scope
+func (cl *Claims) HasScope(scopes ...string) bool {
+	if cl == nil {
+		return false
+	}
+	for _, s := range scopes {
+		found := false
+		for _, v := range cl.Scopes {
+			if v == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// RequireAccess - Allow request with non-empty access of one of levels, 401 without claims and 403 otherwise
+func RequireAccess(levels ...string) echo.MiddlewareFunc {
+	return require(func(cl *Claims) bool {
+		return cl.IsLogin() && cl.HasAccess(levels...)
+	})
+}
+
+// RequireScope - Allow request with every scope, 401 without claims and 403 otherwise
+func RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return require(func(cl *Claims) bool {
+		return cl.HasScope(scopes...)
+	})
+}
+
+// require -
+func require(allow func(cl *Claims) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			cl := GetClaims(c)
+			if cl == nil {
+				return echo.NewHTTPError(401, "unauthorized")
+			}
+			if !allow(cl) {
+				return echo.NewHTTPError(403, "forbidden")
+			}
+			return next(c)
+		}
+	}
+}
+
+// claimInt - Convert JSON number or numeric string claim to int64
+func claimInt(v interface{}) int64 {
+	switch t := v.(type) {
+	case float64:
+		return int64(t)
+	case json.Number:
+		n, _ := t.Int64()
+		return n
+	case string:
+		n, _ := strconv.ParseInt(t, 10, 64)
+		return n
+	}
+	return 0
+}
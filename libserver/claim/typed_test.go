@@ -0,0 +1,92 @@
+package claim
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// withClaims - Echo context with token of claims set like echo JWT middleware, no token when mc is nil
+func withClaims(mc jwt.MapClaims) echo.Context {
+	c := echo.New().NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder())
+	if mc != nil {
+		c.Set("user", &jwt.Token{Claims: mc, Valid: true})
+	}
+	return c
+}
+
+func TestNewClaims(t *testing.T) {
+	cl := NewClaims(jwt.MapClaims{
+		"user_id":    float64(7),
+		"account_id": "8",
+		"access":     "admin",
+		"sid":        "s1",
+		"scope":      "read write",
+		"scopes":     []interface{}{"audit", 1, ""},
+	})
+	if cl.UserID != 7 || cl.AccountID != 8 || cl.Access != "admin" || cl.SessionID != "s1" {
+		t.Errorf("NewClaims() = %+v", cl)
+	}
+	if !cl.HasScope("read", "write", "audit") || cl.HasScope("delete") {
+		t.Errorf("Scopes = %v", cl.Scopes)
+	}
+}
+
+func TestGetJWTAccessValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		ok     bool
+		access string
+	}{
+		{"access", jwt.MapClaims{"access": "user"}, true, "user"},
+		{"empty access", jwt.MapClaims{"access": ""}, true, ""},
+		{"missing access", jwt.MapClaims{"user_id": float64(1)}, false, ""},
+		{"mistyped access", jwt.MapClaims{"access": 1}, false, ""},
+		{"no claims", nil, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, access := GetJWTAccessValue(withClaims(tt.claims))
+			if ok != tt.ok || access != tt.access {
+				t.Errorf("GetJWTAccessValue() = %v, %q, want %v, %q", ok, access, tt.ok, tt.access)
+			}
+		})
+	}
+}
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name   string
+		mw     echo.MiddlewareFunc
+		claims jwt.MapClaims
+		want   int
+	}{
+		{"access allowed", RequireAccess("admin", "user"), jwt.MapClaims{"access": "user"}, 0},
+		{"access other level", RequireAccess("admin"), jwt.MapClaims{"access": "user"}, 403},
+		{"access empty", RequireAccess("admin", ""), jwt.MapClaims{"access": ""}, 403},
+		{"access missing", RequireAccess(""), jwt.MapClaims{"user_id": float64(1)}, 403},
+		{"access no claims", RequireAccess("admin"), nil, 401},
+		{"scope allowed", RequireScope("read"), jwt.MapClaims{"scope": "read write"}, 0},
+		{"scope missing one", RequireScope("read", "delete"), jwt.MapClaims{"scope": "read write"}, 403},
+		{"scope no claims", RequireScope("read"), nil, 401},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.mw(func(c echo.Context) error {
+				return nil
+			})(withClaims(tt.claims))
+			code := 0
+			if he, ok := err.(*echo.HTTPError); ok {
+				code = he.Code
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if code != tt.want {
+				t.Errorf("status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !windows
+// +build !linux,!darwin,!freebsd,!dragonfly,!windows
+
+package libserver
+
+import "fmt"
+
+// diskFree - Free space is not supported on platform
+func diskFree(path string) (uint64, error) {
+	return 0, fmt.Errorf("%s", "disk free space not supported on this platform")
+}
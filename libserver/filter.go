@@ -0,0 +1,269 @@
+package libserver
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/helloferdie/stdgo/libquery"
+	"github.com/helloferdie/stdgo/libresponse"
+	"github.com/helloferdie/stdgo/libslice"
+	"github.com/helloferdie/stdgo/libvalidator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FilterField - Allowed query string filter of list endpoint
+type FilterField struct {
+	Column    string   // Default "`<name>`"
+	Operators []string // Allowed operators, default eq
+	Time      bool     // Parse value as time in Accept-TZ
+	Loc       string   // Locale namespace of field label, default general
+	Collation string   // Collation for equal and like search
+	MinLength int      // Minimum length of search value
+}
+
+// FilterWhitelist - Allowed filters, sort fields and page size of list endpoint
+type FilterWhitelist struct {
+	Fields       map[string]FilterField
+	Sort         []string
+	DefaultSort  string // e.g. "-created_at"
+	DefaultSize  int64
+	MaxSize      int64
+	FullTextMode string
+}
+
+// FilterQuery - Parsed query string filter
+type FilterQuery struct {
+	Configs      []libquery.Config
+	Params       map[string]interface{}
+	OrderParams  map[string]interface{}
+	OrderBy      string // ORDER BY of whitelisted sort, applied through trusted default order of OrderDefault
+	Page         int64
+	ItemsPerPage int64
+}
+
+// filterOperators - Query string operator to libquery condition
+var filterOperators = map[string]string{
+	"eq":          "equal",
+	"ne":          "not_equal",
+	"like":        "like",
+	"starts_with": "starts_with",
+	"ends_with":   "ends_with",
+	"gt":          "gt",
+	"gte":         "gte",
+	"lt":          "lt",
+	"lte":         "lte",
+	"between":     "between",
+	"in":          "in",
+	"nin":         "not in",
+	"null":        "is_null",
+	"not_null":    "not_null",
+	"match":       "fulltext",
+}
+
+// Regex for filter[field] and filter[field][op]
+var regexFilterKey = regexp.MustCompile(`^filter\[([^\[\]]+)\](?:\[([^\[\]]+)\])?$`)
+
+// ParseFilterQuery - Parse ?filter[field][op]=val&sort=-field&page[number]=1&page[size]=50 checked against whitelist
+func ParseFilterQuery(c echo.Context, wl FilterWhitelist) (*FilterQuery, *libresponse.Default, error) {
+	res := libresponse.GetDefault()
+	errData := map[string]*libvalidator.VarValidationError{}
+	errVar := []interface{}{}
+	errMsg := ""
+	addError := func(field string, loc string, e string, v []interface{}) {
+		if _, exist := errData[field]; exist {
+			return
+		}
+		errData[field] = &libvalidator.VarValidationError{Error: e, ErrorVar: v}
+		if errMsg == "" {
+			if loc == "" {
+				loc = "general"
+			}
+			errMsg = e + "_var"
+			errVar = append(errVar, loc+".var_"+field)
+			errVar = append(errVar, v...)
+		}
+	}
+
+	tz := "UTC"
+	if loc, err := time.LoadLocation(c.Request().Header.Get("Accept-TZ")); err == nil {
+		tz = loc.String()
+	}
+
+	fq := &FilterQuery{
+		Configs: []libquery.Config{},
+		Params:  map[string]interface{}{},
+	}
+	query := c.QueryParams()
+	keys := []string{}
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		list := query[key]
+		m := regexFilterKey.FindStringSubmatch(key)
+		if m == nil || len(list) == 0 {
+			continue
+		}
+		name, op := m[1], m[2]
+		if op == "" {
+			op = "eq"
+		}
+
+		field, exist := wl.Fields[name]
+		if !exist {
+			addError(name, "", "general.error_validation_filter_field", []interface{}{})
+			continue
+		}
+		ops := field.Operators
+		if len(ops) == 0 {
+			ops = []string{"eq"}
+		}
+		condition, valid := filterOperators[op]
+		if !valid || !inSlice(op, ops) {
+			addError(name, field.Loc, "general.error_validation_filter_operator", []interface{}{"!" + op})
+			continue
+		}
+
+		param := name + "_" + op
+		cfg := libquery.Config{
+			Condition:    condition,
+			Param:        param,
+			Column:       field.Column,
+			ColumnValue:  param,
+			FullTextMode: wl.FullTextMode,
+			Collation:    field.Collation,
+			MinLength:    field.MinLength,
+		}
+		if cfg.Column == "" {
+			cfg.Column = "`" + name + "`"
+		}
+		if field.Time {
+			cfg.Timezone = tz
+		}
+
+		var value interface{} = list[0]
+		if op == "in" || op == "nin" {
+			value = strings.Split(list[0], ",")
+		}
+
+		// Render once to reject value libquery cannot parse
+		_, _, err := libquery.QueryCondition(cfg, map[string]interface{}{param: value}, "", map[string]interface{}{})
+		if err != nil {
+			if ve, ok := err.(*libquery.ValidationError); ok {
+				addError(name, field.Loc, ve.Key, ve.Var)
+			} else {
+				addError(name, field.Loc, "general.error_validation_filter_value", []interface{}{})
+			}
+			continue
+		}
+		fq.Configs = append(fq.Configs, cfg)
+		fq.Params[param] = value
+	}
+
+	// Sort
+	sortVal := query.Get("sort")
+	if sortVal == "" {
+		sortVal = wl.DefaultSort
+	}
+	orderBy := []string{}
+	for _, s := range strings.Split(sortVal, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		dir := "ASC"
+		if s[0:1] == "-" {
+			dir = "DESC"
+			s = s[1:]
+		} else if s[0:1] == "+" {
+			s = s[1:]
+		}
+		if !inSlice(s, wl.Sort) {
+			addError("sort", "", "general.error_validation_sort_field", []interface{}{"!" + s})
+			continue
+		}
+		column := wl.Fields[s].Column
+		if column == "" {
+			column = "`" + s + "`"
+		}
+		orderBy = append(orderBy, column+" "+dir)
+	}
+
+	// Page
+	fq.Page = 1
+	fq.ItemsPerPage = wl.DefaultSize
+	if fq.ItemsPerPage <= 0 {
+		fq.ItemsPerPage = 10
+	}
+	if v := query.Get("page[number]"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			addError("page_number", "", "general.error_validation_min", []interface{}{"!1"})
+		} else {
+			fq.Page = n
+		}
+	}
+	if v := query.Get("page[size]"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			addError("page_size", "", "general.error_validation_min", []interface{}{"!1"})
+		} else if wl.MaxSize > 0 && n > wl.MaxSize {
+			addError("page_size", "", "general.error_validation_max", []interface{}{"!" + strconv.FormatInt(wl.MaxSize, 10)})
+		} else {
+			fq.ItemsPerPage = n
+		}
+	}
+
+	if len(errData) > 0 {
+		res.Code = 422
+		res.Message = "general.error_validation"
+		res.Error = errMsg
+		res.ErrorVar = errVar
+		res.Data = errData
+		return nil, res, echo.NewHTTPError(422, errMsg)
+	}
+
+	fq.OrderParams = map[string]interface{}{
+		"start": (fq.Page - 1) * fq.ItemsPerPage,
+		"limit": fq.ItemsPerPage,
+	}
+	if len(orderBy) > 0 {
+		fq.OrderBy = "ORDER BY " + strings.Join(orderBy, ", ")
+	}
+	return fq, res, nil
+}
+
+// OrderDefault - Copy of default order config of db.PrepareOrder with validated sort as custom order
+func (fq *FilterQuery) OrderDefault(def map[string]interface{}) map[string]interface{} {
+	m := map[string]interface{}{}
+	for k, v := range def {
+		m[k] = v
+	}
+	if fq.OrderBy != "" {
+		m["custom"] = fq.OrderBy
+	}
+	return m
+}
+
+// Condition - Append parsed filters to condition
+func (fq *FilterQuery) Condition(condition string, values map[string]interface{}) (string, map[string]interface{}, error) {
+	for _, cfg := range fq.Configs {
+		var err error
+		condition, values, err = libquery.QueryCondition(cfg, fq.Params, condition, values)
+		if err != nil {
+			return condition, values, err
+		}
+	}
+	return condition, values, nil
+}
+
+// inSlice - Check list contains value
+func inSlice(a string, list []string) bool {
+	_, exist := libslice.Contains(a, list)
+	return exist
+}
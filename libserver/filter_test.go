@@ -0,0 +1,68 @@
+package libserver
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// queryEscaper - Escape character of test query invalid in request URI
+var queryEscaper = strings.NewReplacer("[", "%5B", "]", "%5D", "`", "%60", " ", "%20", "(", "%28", ")", "%29")
+
+func TestParseFilterQuery(t *testing.T) {
+	wl := FilterWhitelist{
+		Fields: map[string]FilterField{
+			"label":      {Operators: []string{"eq", "like"}},
+			"created_at": {Operators: []string{"gte"}, Time: true},
+		},
+		Sort:        []string{"label", "created_at"},
+		DefaultSort: "-created_at",
+		DefaultSize: 10,
+		MaxSize:     50,
+	}
+	tests := []struct {
+		name    string
+		query   string
+		code    int64
+		orderBy string
+		page    int64
+		size    int64
+		params  int
+	}{
+		{name: "default", orderBy: "ORDER BY `created_at` DESC", page: 1, size: 10},
+		{name: "filter and sort", query: "filter[label][like]=en&sort=label,-created_at&page[number]=2&page[size]=20", orderBy: "ORDER BY `label` ASC, `created_at` DESC", page: 2, size: 20, params: 1},
+		{name: "unknown field", query: "filter[secret]=1", code: 422},
+		{name: "operator not allowed", query: "filter[label][gt]=1", code: 422},
+		{name: "sort not allowed", query: "sort=secret", code: 422},
+		{name: "sort injection", query: "sort=label`,(SELECT 1)", code: 422},
+		{name: "page size over max", query: "page[size]=100", code: 422},
+		{name: "invalid page", query: "page[number]=0", code: 422},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest("GET", "/?"+queryEscaper.Replace(tt.query), nil), httptest.NewRecorder())
+			fq, res, err := ParseFilterQuery(c, wl)
+			if tt.code != 0 {
+				if err == nil || res.Code != tt.code {
+					t.Errorf("code = %d err = %v, want %d", res.Code, err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if fq.OrderBy != tt.orderBy || fq.Page != tt.page || fq.ItemsPerPage != tt.size || len(fq.Params) != tt.params {
+				t.Errorf("got order %q page %d size %d params %d", fq.OrderBy, fq.Page, fq.ItemsPerPage, len(fq.Params))
+			}
+			if _, exist := fq.OrderParams["custom"]; exist {
+				t.Error("custom order must not be in order params")
+			}
+			if def := fq.OrderDefault(map[string]interface{}{"field": "id"}); def["custom"] != tt.orderBy || def["field"] != "id" {
+				t.Errorf("OrderDefault() = %v", def)
+			}
+		})
+	}
+}
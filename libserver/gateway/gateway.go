@@ -0,0 +1,278 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/helloferdie/stdgo/libhttp"
+	"github.com/helloferdie/stdgo/libresponse"
+	"github.com/helloferdie/stdgo/libserver"
+	"github.com/helloferdie/stdgo/libserver/auth"
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Route - Forward request of path prefix to upstream pool
+type Route struct {
+	Prefix       string        // e.g. "/v1/audit"
+	Upstreams    []string      // Upstream base URL, picked in round robin
+	StripPrefix  bool          // Remove prefix before forwarding, removed prefix is sent in X-Forwarded-Prefix
+	Timeout      time.Duration // Whole request including retries, default 30 seconds
+	Retries      int           // Retry idempotent method on next upstream when unreachable or 502 / 503 / 504
+	MaxRetryBody int64         // Buffered request body size to allow retry, default 1 MB
+	Auth         *auth.Config  // Verify JWT before forwarding when set
+}
+
+// Gateway - Reverse proxy of route table, localize upstream response with libserver.ResponseProxy
+type Gateway struct {
+	Transport http.RoundTripper // Default http.DefaultTransport
+	routes    []*route
+}
+
+// route -
+type route struct {
+	Route
+	targets []*url.URL
+	next    uint32
+	proxy   *httputil.ReverseProxy
+	handler echo.HandlerFunc
+}
+
+// contextKey - Request context key of echo context
+type contextKey struct{}
+
+// New - Create gateway of routes
+func New(routes ...Route) (*Gateway, error) {
+	g := &Gateway{}
+	for _, r := range routes {
+		if err := g.Add(r); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Add - Add route, longest prefix is matched first
+func (g *Gateway) Add(r Route) error {
+	if len(r.Upstreams) == 0 {
+		return fmt.Errorf("route %s has no upstream", r.Prefix)
+	}
+	if r.Timeout <= 0 {
+		r.Timeout = 30 * time.Second
+	}
+	if r.MaxRetryBody <= 0 {
+		r.MaxRetryBody = 1 << 20
+	}
+	rt := &route{Route: r}
+	for _, u := range r.Upstreams {
+		target, err := url.Parse(u)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return fmt.Errorf("route %s has invalid upstream %s", r.Prefix, u)
+		}
+		rt.targets = append(rt.targets, target)
+	}
+
+	rt.proxy = &httputil.ReverseProxy{
+		Director:       func(req *http.Request) {},
+		Transport:      &retryTransport{route: rt, gateway: g},
+		ModifyResponse: libserver.ResponseProxy,
+		ErrorHandler:   errorHandler,
+	}
+	rt.handler = rt.serve
+	if r.Auth != nil {
+		rt.handler = auth.Middleware(*r.Auth)(rt.handler)
+	}
+
+	g.routes = append(g.routes, rt)
+	sort.SliceStable(g.routes, func(i, j int) bool {
+		return len(g.routes[i].Prefix) > len(g.routes[j].Prefix)
+	})
+	return nil
+}
+
+// Handler - Echo handler of gateway, e.g. e.Any("/*", g.Handler())
+func (g *Gateway) Handler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		rt := g.match(c.Request().URL.Path)
+		if rt == nil {
+			return echo.ErrNotFound
+		}
+		return rt.handler(c)
+	}
+}
+
+// match - Return route of path
+func (g *Gateway) match(path string) *route {
+	for _, rt := range g.routes {
+		p := strings.TrimSuffix(rt.Prefix, "/")
+		if path == p || strings.HasPrefix(path, p+"/") || p == "" {
+			return rt
+		}
+	}
+	return nil
+}
+
+// serve - Forward request to upstream
+func (rt *route) serve(c echo.Context) error {
+	req := c.Request()
+	ctx, cancel := context.WithTimeout(req.Context(), rt.Timeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, contextKey{}, c)
+
+	out := req.WithContext(ctx)
+	out.URL = new(url.URL)
+	*out.URL = *req.URL
+	if rt.StripPrefix {
+		// Original path is needed by upstream to verify libhttp signature
+		out.Header = req.Header.Clone()
+		out.Header.Set(libhttp.HeaderForwardedPrefix, strings.TrimSuffix(rt.Prefix, "/"))
+		out.URL.Path = strings.TrimPrefix(out.URL.Path, strings.TrimSuffix(rt.Prefix, "/"))
+		out.URL.RawPath = ""
+		if !strings.HasPrefix(out.URL.Path, "/") {
+			out.URL.Path = "/" + out.URL.Path
+		}
+	}
+	// Buffer body of idempotent request with known length to replay on retry, chunked body is streamed without retry
+	if rt.Retries > 0 && isIdempotent(req.Method) && req.Body != nil && req.Body != http.NoBody && req.ContentLength >= 0 && req.ContentLength <= rt.MaxRetryBody {
+		b, err := ioutil.ReadAll(http.MaxBytesReader(c.Response(), req.Body, rt.MaxRetryBody))
+		if err != nil {
+			return echo.NewHTTPError(413, err.Error())
+		}
+		out.Body = ioutil.NopCloser(bytes.NewReader(b))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(b)), nil
+		}
+	}
+
+	rt.proxy.ServeHTTP(c.Response(), out)
+	return nil
+}
+
+// retryTransport - Send request to upstream of route and retry idempotent request on next upstream
+type retryTransport struct {
+	route   *route
+	gateway *Gateway
+}
+
+// RoundTrip -
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.gateway.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	rt := t.route
+	attempts := 1
+	if isIdempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil) {
+		attempts += rt.Retries
+	}
+
+	start := atomic.AddUint32(&rt.next, 1) - 1
+	path, rawQuery := req.URL.Path, req.URL.RawQuery
+	var res *http.Response
+	var err error
+	for i := 0; i < attempts; i++ {
+		target := rt.targets[(int(start)+i)%len(rt.targets)]
+		out := req.Clone(req.Context())
+		out.URL.Scheme = target.Scheme
+		out.URL.Host = target.Host
+		out.URL.Path = joinPath(target.Path, path)
+		out.URL.RawPath = ""
+		out.URL.RawQuery = joinQuery(target.RawQuery, rawQuery)
+		out.Host = target.Host
+		if i > 0 && req.GetBody != nil {
+			if out.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err = base.RoundTrip(out)
+		if err == nil && !retryStatus(res.StatusCode) {
+			return res, nil
+		}
+		if req.Context().Err() != nil || i == attempts-1 {
+			break
+		}
+		if err == nil {
+			res.Body.Close()
+		}
+		logger.MakeLogEntryContext(req.Context(), false).Errorf("Retry %s %s on next upstream: %v", req.Method, path, retryReason(res, err))
+	}
+	return res, err
+}
+
+// errorHandler - Respond unreachable upstream with 502 general.error_service_unreachable, route timeout with 504 general.error_gateway_timeout
+func errorHandler(w http.ResponseWriter, req *http.Request, err error) {
+	logger.MakeLogEntryContext(req.Context(), false).Errorf("Gateway fail to reach upstream %s: %v", req.URL.Path, err)
+	code := 502
+	if errors.Is(err, context.DeadlineExceeded) || req.Context().Err() == context.DeadlineExceeded {
+		code = 504
+	}
+	c, ok := req.Context().Value(contextKey{}).(echo.Context)
+	if !ok {
+		w.WriteHeader(code)
+		return
+	}
+	res := libresponse.GetDefault()
+	res.Code = int64(code)
+	res.Message = "general.error_gateway"
+	res.Error = "general.error_service_unreachable"
+	if code == 504 {
+		res.Error = "general.error_gateway_timeout"
+	}
+	libserver.Response(c, res)
+}
+
+// isIdempotent -
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// retryStatus - Upstream status worth retry on next upstream
+func retryStatus(code int) bool {
+	return code == 502 || code == 503 || code == 504
+}
+
+// retryReason -
+func retryReason(res *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return res.Status
+}
+
+// joinPath - Join upstream base path and request path with single slash
+func joinPath(a string, b string) string {
+	switch {
+	case a == "":
+		return b
+	case strings.HasSuffix(a, "/") && strings.HasPrefix(b, "/"):
+		return a + b[1:]
+	case !strings.HasSuffix(a, "/") && !strings.HasPrefix(b, "/"):
+		return a + "/" + b
+	}
+	return a + b
+}
+
+// joinQuery -
+func joinQuery(a string, b string) string {
+	if a == "" || b == "" {
+		return a + b
+	}
+	return a + "&" + b
+}
@@ -0,0 +1,200 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/libhttp"
+	"github.com/helloferdie/stdgo/libserver"
+
+	"github.com/labstack/echo/v4"
+)
+
+// upstream - Test upstream counting hits and echoing path and body as message
+func upstream(t *testing.T, status int, delay time.Duration, hits *int32) string {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		b, _ := ioutil.ReadAll(r.Body)
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+		w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": status < 300,
+			"code":    status,
+			"message": r.URL.Path + " " + string(b),
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s.URL
+}
+
+func TestGateway(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       io.Reader
+		chunked    bool
+		statuses   []int
+		delay      time.Duration
+		route      Route
+		status     int
+		hits       []int32
+		resMessage string
+	}{
+		{
+			name:       "forward with strip prefix",
+			method:     "GET",
+			path:       "/v1/audit/list",
+			statuses:   []int{200},
+			route:      Route{Prefix: "/v1/audit", StripPrefix: true},
+			status:     200,
+			hits:       []int32{1},
+			resMessage: "/list ",
+		},
+		{
+			name:     "retry idempotent request on next upstream",
+			method:   "GET",
+			path:     "/v1/audit",
+			statuses: []int{503, 200},
+			route:    Route{Prefix: "/v1/audit", Retries: 1},
+			status:   200,
+			hits:     []int32{1, 1},
+		},
+		{
+			name:     "no retry of post",
+			method:   "POST",
+			path:     "/v1/audit",
+			body:     strings.NewReader("x"),
+			statuses: []int{503, 200},
+			route:    Route{Prefix: "/v1/audit", Retries: 1},
+			status:   503,
+			hits:     []int32{1, 0},
+		},
+		{
+			name:       "replay buffered body of put on retry",
+			method:     "PUT",
+			path:       "/v1/audit",
+			body:       strings.NewReader("payload"),
+			statuses:   []int{502, 200},
+			route:      Route{Prefix: "/v1/audit", Retries: 1},
+			status:     200,
+			hits:       []int32{1, 1},
+			resMessage: "/v1/audit payload",
+		},
+		{
+			name:     "stream chunked body larger than max retry body",
+			method:   "PUT",
+			path:     "/v1/audit",
+			body:     bytes.NewReader(bytes.Repeat([]byte("a"), 2048)),
+			chunked:  true,
+			statuses: []int{200, 200},
+			route:    Route{Prefix: "/v1/audit", Retries: 1, MaxRetryBody: 1024},
+			status:   200,
+			hits:     []int32{1, 0},
+		},
+		{
+			name:     "stream known body larger than max retry body",
+			method:   "PUT",
+			path:     "/v1/audit",
+			body:     bytes.NewReader(bytes.Repeat([]byte("a"), 2048)),
+			statuses: []int{200},
+			route:    Route{Prefix: "/v1/audit", Retries: 1, MaxRetryBody: 1024},
+			status:   200,
+			hits:     []int32{1},
+		},
+		{
+			name:     "timeout",
+			method:   "GET",
+			path:     "/v1/audit",
+			statuses: []int{200},
+			delay:    200 * time.Millisecond,
+			route:    Route{Prefix: "/v1/audit", Timeout: 50 * time.Millisecond},
+			status:   504,
+			hits:     []int32{1},
+		},
+		{
+			name:   "unreachable upstream",
+			method: "GET",
+			path:   "/v1/audit",
+			route:  Route{Prefix: "/v1/audit", Upstreams: []string{"http://127.0.0.1:1"}},
+			status: 502,
+		},
+		{
+			name:   "no route",
+			method: "GET",
+			path:   "/v2",
+			route:  Route{Prefix: "/v1/audit", Upstreams: []string{"http://127.0.0.1:1"}},
+			status: 404,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := make([]int32, len(tt.statuses))
+			for i, status := range tt.statuses {
+				tt.route.Upstreams = append(tt.route.Upstreams, upstream(t, status, tt.delay, &hits[i]))
+			}
+			g, err := New(tt.route)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := echo.New()
+			e.HTTPErrorHandler = libserver.ErrorHandler
+			e.Any("/*", g.Handler())
+
+			req := httptest.NewRequest(tt.method, tt.path, tt.body)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.status, rec.Body.String())
+			}
+			for i := range tt.hits {
+				if got := atomic.LoadInt32(&hits[i]); got != tt.hits[i] {
+					t.Errorf("upstream %d hits = %d, want %d", i, got, tt.hits[i])
+				}
+			}
+			if tt.resMessage != "" && !strings.Contains(rec.Body.String(), `"message":"`+tt.resMessage+`"`) {
+				t.Errorf("body = %q, want message %q", rec.Body.String(), tt.resMessage)
+			}
+		})
+	}
+}
+
+func TestGatewaySignatureStripPrefix(t *testing.T) {
+	up := echo.New()
+	up.POST("/audit", func(c echo.Context) error {
+		return c.JSON(200, map[string]interface{}{"success": true, "code": 200, "message": "ok"})
+	}, libserver.VerifySignature(libserver.SignatureConfig{Keys: map[string]string{"k1": "secret"}}))
+	s := httptest.NewServer(up)
+	defer s.Close()
+
+	g, err := New(Route{Prefix: "/v1", Upstreams: []string{s.URL}, StripPrefix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.Any("/*", g.Handler())
+
+	body := []byte(`{"a":1}`)
+	req := httptest.NewRequest("POST", "/v1/audit", bytes.NewReader(body))
+	(&libhttp.Signer{KeyID: "k1", Secret: []byte("secret")}).Sign(req, body)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != 200 {
+		t.Errorf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+}
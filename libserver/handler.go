@@ -19,8 +19,11 @@ func Ping(c echo.Context) (err error) {
 	return Response(c, res)
 }
 
-// ErrorHandler -
+// ErrorHandler - Respond error, skipped when response is committed, e.g. error handled by middleware with c.Error
 func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
 	res := libresponse.GetDefault()
 	report, ok := err.(*echo.HTTPError)
 	if !ok {
@@ -73,6 +76,9 @@ func ErrorHandler(err error, c echo.Context) {
 	case 405:
 		res.Message = "general.error_request"
 		res.Error = "general.error_method_not_allowed"
+	case 409:
+		res.Message = "general.error_request"
+		res.Error = "general.error_conflict"
 	case 413:
 		res.Message = "general.error_request"
 		res.Error = "general.error_request_too_large"
@@ -81,6 +87,9 @@ func ErrorHandler(err error, c echo.Context) {
 		res.Error = "general.error_unsupported_media_type"
 	case 422:
 		res.Message = "general.error_validation"
+	case 429:
+		res.Message = "general.error_request"
+		res.Error = "general.error_too_many_requests"
 	case 500:
 		res.Message = "general.error_internal"
 		if res.Error == "" {
@@ -89,6 +98,12 @@ func ErrorHandler(err error, c echo.Context) {
 	case 502:
 		res.Message = "general.error_gateway"
 		res.Error = "general.error_service_unreachable"
+	case 503:
+		res.Message = "general.error_service_unavailable"
+		res.Error = "general.error_service_unavailable"
+	case 504:
+		res.Message = "general.error_gateway"
+		res.Error = "general.error_gateway_timeout"
 	}
 	Response(c, res)
 }
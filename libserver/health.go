@@ -0,0 +1,234 @@
+package libserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/helloferdie/stdgo/librabbitmq"
+	"github.com/helloferdie/stdgo/libresponse"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/labstack/echo/v4"
+)
+
+// HealthChecker - Check component health, return error when unhealthy
+type HealthChecker func(ctx context.Context) error
+
+// HealthCheck - Registered health check
+type HealthCheck struct {
+	Name     string
+	Check    HealthChecker
+	Timeout  time.Duration // Default 2 seconds
+	CacheTTL time.Duration // Reuse last result within, default 5 seconds
+	Optional bool          // Report status without failing readiness
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	latency   time.Duration
+	err       error
+}
+
+// HealthStatus - Status of single component
+type HealthStatus struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	Optional  bool   `json:"optional,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+	CheckedAt string `json:"checked_at"`
+}
+
+// Health - Registry of health checks
+type Health struct {
+	mu     sync.RWMutex
+	checks []*HealthCheck
+}
+
+// DefaultHealth - Health registry served by Initialize
+var DefaultHealth = NewHealth()
+
+// NewHealth - Create health registry
+func NewHealth() *Health {
+	return &Health{checks: []*HealthCheck{}}
+}
+
+// RegisterHealthCheck - Register check to default health registry
+func RegisterHealthCheck(hc *HealthCheck) {
+	DefaultHealth.Register(hc)
+}
+
+// Register - Register check, replace check with same name
+func (h *Health) Register(hc *HealthCheck) {
+	if hc.Timeout <= 0 {
+		hc.Timeout = 2 * time.Second
+	}
+	if hc.CacheTTL <= 0 {
+		hc.CacheTTL = 5 * time.Second
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for k, v := range h.checks {
+		if v.Name == hc.Name {
+			h.checks[k] = hc
+			return
+		}
+	}
+	h.checks = append(h.checks, hc)
+}
+
+// Check - Run every check concurrently and return status per component and overall readiness
+func (h *Health) Check(ctx context.Context) (map[string]*HealthStatus, bool) {
+	h.mu.RLock()
+	checks := make([]*HealthCheck, len(h.checks))
+	copy(checks, h.checks)
+	h.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	result := map[string]*HealthStatus{}
+	ready := true
+	for _, hc := range checks {
+		wg.Add(1)
+		go func(hc *HealthCheck) {
+			defer wg.Done()
+			st := hc.run(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			result[hc.Name] = st
+			if st.Status != "up" && !hc.Optional {
+				ready = false
+			}
+		}(hc)
+	}
+	wg.Wait()
+	return result, ready
+}
+
+// run - Run check with timeout or return cached result
+func (hc *HealthCheck) run(ctx context.Context) *HealthStatus {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	if hc.checkedAt.IsZero() || time.Since(hc.checkedAt) >= hc.CacheTTL {
+		start := time.Now()
+		hc.err = runWithTimeout(ctx, hc.Timeout, hc.Check)
+		hc.latency = time.Since(start)
+		hc.checkedAt = time.Now()
+	}
+
+	st := &HealthStatus{
+		Status:    "up",
+		Optional:  hc.Optional,
+		LatencyMS: hc.latency.Milliseconds(),
+		CheckedAt: hc.checkedAt.UTC().Format(time.RFC3339),
+	}
+	if hc.err != nil {
+		st.Status = "down"
+		st.Error = hc.err.Error()
+	}
+	return st
+}
+
+// runWithTimeout - Run checker and give up after timeout even if checker ignore ctx
+func runWithTimeout(ctx context.Context, timeout time.Duration, check HealthChecker) error {
+	if check == nil {
+		return fmt.Errorf("%s", "checker not defined")
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("checker panic: %v", r)
+			}
+		}()
+		done <- check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%s", "timeout")
+	}
+}
+
+// Live - Liveness endpoint, process is up and serving
+func (h *Health) Live(c echo.Context) error {
+	res := libresponse.GetDefault()
+	res.Success = true
+	res.Code = 200
+	res.Message = "general.success"
+	res.Data = map[string]interface{}{
+		"status": "up",
+	}
+	return Response(c, res)
+}
+
+// Ready - Readiness endpoint, 503 when a required component is down
+func (h *Health) Ready(c echo.Context) error {
+	result, ready := h.Check(c.Request().Context())
+	res := libresponse.GetDefault()
+	res.Data = map[string]interface{}{
+		"components": result,
+	}
+	if ready {
+		res.Success = true
+		res.Code = 200
+		res.Message = "general.success"
+		res.Data.(map[string]interface{})["status"] = "up"
+	} else {
+		res.Code = 503
+		res.Message = "general.error_service_unavailable"
+		res.Error = "general.error_health_check"
+		res.Data.(map[string]interface{})["status"] = "down"
+	}
+	return Response(c, res)
+}
+
+// DBChecker - Check database connection with ping
+func DBChecker(d *sqlx.DB) HealthChecker {
+	return func(ctx context.Context) error {
+		if d == nil {
+			return fmt.Errorf("%s", "database not connected")
+		}
+		return d.PingContext(ctx)
+	}
+}
+
+// RabbitMQChecker - Check rabbitmq resource connection is open, dial server when resource is nil or idle publisher
+func RabbitMQChecker(r *librabbitmq.Resource) HealthChecker {
+	return func(ctx context.Context) error {
+		if r == nil || r.IsIdle() {
+			return librabbitmq.Ping(ctx)
+		}
+		if !r.IsConnected() {
+			return fmt.Errorf("%s", "connection closed")
+		}
+		return nil
+	}
+}
+
+// DiskChecker - Check free space of path is at least minFree bytes, default dir_log
+func DiskChecker(path string, minFree uint64) HealthChecker {
+	return func(ctx context.Context) error {
+		if path == "" {
+			path = os.Getenv("dir_log")
+		}
+		if path == "" {
+			path = os.TempDir()
+		}
+		free, err := diskFree(path)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("free space %d bytes below %d bytes", free, minFree)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,170 @@
+package libserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/librabbitmq"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestHealthCheck(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return fmt.Errorf("%s", "refused") }
+	hang := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+	tests := []struct {
+		name   string
+		checks []*HealthCheck
+		status map[string]string
+		errors map[string]string
+		ready  bool
+	}{
+		{"no check", nil, map[string]string{}, map[string]string{}, true},
+		{"every check up", []*HealthCheck{{Name: "db", Check: up}, {Name: "mq", Check: up}}, map[string]string{"db": "up", "mq": "up"}, map[string]string{}, true},
+		{"required down", []*HealthCheck{{Name: "db", Check: up}, {Name: "mq", Check: down}}, map[string]string{"db": "up", "mq": "down"}, map[string]string{"mq": "refused"}, false},
+		{"optional down", []*HealthCheck{{Name: "db", Check: up}, {Name: "mq", Check: down, Optional: true}}, map[string]string{"db": "up", "mq": "down"}, map[string]string{"mq": "refused"}, true},
+		{"timeout", []*HealthCheck{{Name: "db", Check: hang, Timeout: 10 * time.Millisecond}}, map[string]string{"db": "down"}, map[string]string{"db": "timeout"}, false},
+		{"panic", []*HealthCheck{{Name: "db", Check: func(ctx context.Context) error { panic("boom") }}}, map[string]string{"db": "down"}, map[string]string{"db": "checker panic: boom"}, false},
+		{"nil checker", []*HealthCheck{{Name: "db"}}, map[string]string{"db": "down"}, map[string]string{"db": "checker not defined"}, false},
+		{"same name replaced", []*HealthCheck{{Name: "db", Check: down}, {Name: "db", Check: up}}, map[string]string{"db": "up"}, map[string]string{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHealth()
+			for _, hc := range tt.checks {
+				h.Register(hc)
+			}
+			start := time.Now()
+			result, ready := h.Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Check() took %v", elapsed)
+			}
+			if ready != tt.ready {
+				t.Errorf("ready = %v, want %v", ready, tt.ready)
+			}
+			if len(result) != len(tt.status) {
+				t.Errorf("result = %v, want %v", result, tt.status)
+			}
+			for name, st := range result {
+				if st.Status != tt.status[name] || st.Error != tt.errors[name] {
+					t.Errorf("%s = %s %q, want %s %q", name, st.Status, st.Error, tt.status[name], tt.errors[name])
+				}
+			}
+		})
+	}
+}
+
+func TestHealthCheckCache(t *testing.T) {
+	count := 0
+	h := NewHealth()
+	h.Register(&HealthCheck{Name: "db", CacheTTL: 50 * time.Millisecond, Check: func(ctx context.Context) error {
+		count++
+		return nil
+	}})
+	h.Check(context.Background())
+	h.Check(context.Background())
+	if count != 1 {
+		t.Errorf("checked %d times within cache TTL, want 1", count)
+	}
+	time.Sleep(60 * time.Millisecond)
+	h.Check(context.Background())
+	if count != 2 {
+		t.Errorf("checked %d times after cache TTL, want 2", count)
+	}
+}
+
+func TestHealthLiveReady(t *testing.T) {
+	down := NewHealth()
+	down.Register(&HealthCheck{Name: "db", Check: DBChecker(nil)})
+	down.Register(&HealthCheck{Name: "disk", Check: DiskChecker(t.TempDir(), 0)})
+	optional := NewHealth()
+	optional.Register(&HealthCheck{Name: "db", Check: DBChecker(nil), Optional: true})
+
+	tests := []struct {
+		name    string
+		handler echo.HandlerFunc
+		code    int
+		status  string
+	}{
+		{"live while component down", down.Live, 200, "up"},
+		{"ready of required down", down.Ready, 503, "down"},
+		{"ready of optional down", optional.Ready, 200, "up"},
+		{"ready without check", NewHealth().Ready, 200, "up"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest("GET", "/", nil), rec)
+			if err := tt.handler(c); err != nil {
+				t.Fatal(err)
+			}
+			body := struct {
+				Data struct {
+					Status     string                   `json:"status"`
+					Components map[string]*HealthStatus `json:"components"`
+				} `json:"data"`
+			}{}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if rec.Code != tt.code || body.Data.Status != tt.status {
+				t.Errorf("response = %d %q, want %d %q", rec.Code, body.Data.Status, tt.code, tt.status)
+			}
+		})
+	}
+}
+
+func TestHealthCheckers(t *testing.T) {
+	// Listener accept connection and never answer amqp handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	defer os.Unsetenv("amqp_connection")
+	os.Setenv("amqp_connection", "amqp://guest:guest@"+ln.Addr().String()+"/")
+
+	tests := []struct {
+		name  string
+		check HealthChecker
+		valid bool
+	}{
+		{"db not connected", DBChecker(nil), false},
+		{"disk free", DiskChecker(t.TempDir(), 0), true},
+		{"disk below minimum", DiskChecker(t.TempDir(), 1<<62), false},
+		{"disk path not exist", DiskChecker(t.TempDir()+"/missing", 0), false},
+		{"rabbitmq resource nil", RabbitMQChecker(nil), false},
+		{"rabbitmq resource idle", RabbitMQChecker(&librabbitmq.Resource{}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			err := tt.check(ctx)
+			if (err == nil) != tt.valid {
+				t.Errorf("check error = %v, want valid %v", err, tt.valid)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("check took %v, ctx deadline ignored", elapsed)
+			}
+		})
+	}
+}
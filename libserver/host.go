@@ -0,0 +1,217 @@
+package libserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/helloferdie/stdgo/libresponse"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CertificateFunc - Return certificate for TLS handshake, signature of tls.Config GetCertificate
+type CertificateFunc func(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+
+// HostRouter - Route request to echo instance by host name or wildcard pattern and pick certificate by SNI,
+// wildcard match a single label like TLS certificate, e.g. *.example.com match a.example.com but not a.b.example.com
+type HostRouter struct {
+	mu        sync.RWMutex
+	hosts     map[string]*hostEntry
+	wildcards []*hostEntry
+	fallback  *echo.Echo
+	notFound  *echo.Echo
+	defCert   CertificateFunc
+}
+
+// hostEntry -
+type hostEntry struct {
+	pattern string
+	echo    *echo.Echo
+	cert    CertificateFunc
+}
+
+// NewHostRouter - Create host router
+func NewHostRouter() *HostRouter {
+	notFound := echo.New()
+	notFound.HideBanner = true
+	return &HostRouter{
+		hosts:    map[string]*hostEntry{},
+		notFound: notFound,
+	}
+}
+
+// Add - Route host name or wildcard pattern to echo instance
+func (h *HostRouter) Add(pattern string, e *echo.Echo) {
+	h.set(pattern, e, nil)
+}
+
+// AddTLS - Route host name or wildcard pattern to echo instance with its certificate and key file
+func (h *HostRouter) AddTLS(pattern string, e *echo.Echo, certFile string, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("fail to load certificate of host %s: %v", pattern, err)
+	}
+	h.set(pattern, e, func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return &cert, nil
+	})
+	return nil
+}
+
+// AddCertificateFunc - Route host name or wildcard pattern to echo instance with certificate func, e.g. from certificate manager
+func (h *HostRouter) AddCertificateFunc(pattern string, e *echo.Echo, cert CertificateFunc) {
+	h.set(pattern, e, cert)
+}
+
+// SetFallback - Set echo instance for unknown host, default respond 404
+func (h *HostRouter) SetFallback(e *echo.Echo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = e
+}
+
+// SetDefaultCertificate - Set certificate for client without SNI or unknown host
+func (h *HostRouter) SetDefaultCertificate(cert CertificateFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.defCert = cert
+}
+
+// set -
+func (h *HostRouter) set(pattern string, e *echo.Echo, cert CertificateFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pattern = normalizeHost(pattern)
+	entry := &hostEntry{pattern: pattern, echo: e, cert: cert}
+	if strings.HasPrefix(pattern, "*.") {
+		list := []*hostEntry{entry}
+		for _, w := range h.wildcards {
+			if w.pattern != pattern {
+				list = append(list, w)
+			}
+		}
+		// Longest pattern first, keep order of Echos stable
+		sort.SliceStable(list, func(i, j int) bool {
+			return len(list[i].pattern) > len(list[j].pattern)
+		})
+		h.wildcards = list
+	} else {
+		h.hosts[pattern] = entry
+	}
+	if h.defCert == nil && cert != nil {
+		h.defCert = cert
+	}
+}
+
+// match - Return entry of host
+func (h *HostRouter) match(host string) *hostEntry {
+	host = normalizeHost(host)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if entry, exist := h.hosts[host]; exist {
+		return entry
+	}
+	i := strings.IndexByte(host, '.')
+	if i <= 0 {
+		return nil
+	}
+	for _, entry := range h.wildcards {
+		if host[i:] == entry.pattern[1:] {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Match - Return echo instance of host, nil if unknown
+func (h *HostRouter) Match(host string) *echo.Echo {
+	entry := h.match(host)
+	if entry == nil {
+		return nil
+	}
+	return entry.echo
+}
+
+// Echos - Return every echo instance of host in host order, then wildcard and fallback
+func (h *HostRouter) Echos() []*echo.Echo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	hosts := make([]string, 0, len(h.hosts))
+	for host := range h.hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	entries := []*hostEntry{}
+	for _, host := range hosts {
+		entries = append(entries, h.hosts[host])
+	}
+	entries = append(entries, h.wildcards...)
+	if h.fallback != nil {
+		entries = append(entries, &hostEntry{echo: h.fallback})
+	}
+
+	list := []*echo.Echo{}
+	seen := map[*echo.Echo]bool{}
+	for _, entry := range entries {
+		if !seen[entry.echo] {
+			seen[entry.echo] = true
+			list = append(list, entry.echo)
+		}
+	}
+	return list
+}
+
+// ServeHTTP - Serve request with echo instance of host, respond localized 404 for unknown host
+func (h *HostRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if entry := h.match(r.Host); entry != nil {
+		entry.echo.ServeHTTP(w, r)
+		return
+	}
+
+	h.mu.RLock()
+	fallback := h.fallback
+	h.mu.RUnlock()
+	if fallback != nil {
+		fallback.ServeHTTP(w, r)
+		return
+	}
+
+	c := h.notFound.NewContext(r, w)
+	res := libresponse.GetDefault()
+	res.Code = 404
+	res.Message = "general.error_request"
+	res.Error = "general.error_not_found"
+	Response(c, res)
+}
+
+// GetCertificate - Pick certificate by SNI server name, use as tls.Config GetCertificate
+func (h *HostRouter) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if hello.ServerName != "" {
+		if entry := h.match(hello.ServerName); entry != nil && entry.cert != nil {
+			return entry.cert(hello)
+		}
+	}
+
+	h.mu.RLock()
+	defCert := h.defCert
+	h.mu.RUnlock()
+	if defCert == nil {
+		return nil, fmt.Errorf("no certificate for host %s", hello.ServerName)
+	}
+	return defCert(hello)
+}
+
+// normalizeHost - Lower case host and remove port and trailing dot
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
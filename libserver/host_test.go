@@ -0,0 +1,119 @@
+package libserver
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// hostEcho - Echo instance respond its name
+func hostEcho(name string) *echo.Echo {
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.String(200, name)
+	})
+	return e
+}
+
+// hostCert - Certificate func return certificate of name in OCSP staple
+func hostCert(name string) CertificateFunc {
+	return func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return &tls.Certificate{OCSPStaple: []byte(name)}, nil
+	}
+}
+
+func TestHostRouterServeHTTP(t *testing.T) {
+	hr := NewHostRouter()
+	hr.Add("Example.com", hostEcho("apex"))
+	hr.Add("*.example.com", hostEcho("wildcard"))
+	hr.Add("*.api.example.com", hostEcho("api"))
+	hr.Add("www.example.com", hostEcho("www"))
+	wildcard := NewHostRouter()
+	wildcard.Add("*.example.com", hostEcho("wildcard"))
+	fallback := NewHostRouter()
+	fallback.Add("example.com", hostEcho("apex"))
+	fallback.SetFallback(hostEcho("fallback"))
+
+	tests := []struct {
+		name string
+		hr   *HostRouter
+		host string
+		code int
+		body string
+	}{
+		{"exact", hr, "example.com", 200, "apex"},
+		{"case port and trailing dot", hr, "EXAMPLE.com.:8080", 200, "apex"},
+		{"exact before wildcard", hr, "www.example.com", 200, "www"},
+		{"wildcard single label", hr, "a.example.com", 200, "wildcard"},
+		{"wildcard of subdomain", hr, "a.api.example.com", 200, "api"},
+		{"wildcard not match many labels", hr, "a.b.example.com", 404, ""},
+		{"wildcard not match apex", wildcard, "example.com", 404, ""},
+		{"wildcard not match suffix of label", hr, "aexample.com", 404, ""},
+		{"unknown host", hr, "example.org", 404, ""},
+		{"fallback", fallback, "a.example.com", 200, "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			tt.hr.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.code)
+			}
+			if tt.body != "" && rec.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestHostRouterGetCertificate(t *testing.T) {
+	hr := NewHostRouter()
+	hr.AddCertificateFunc("example.com", hostEcho("apex"), hostCert("apex"))
+	hr.AddCertificateFunc("*.example.com", hostEcho("wildcard"), hostCert("wildcard"))
+	hr.Add("plain.example.org", hostEcho("plain"))
+	withDefault := NewHostRouter()
+	withDefault.AddCertificateFunc("example.com", hostEcho("apex"), hostCert("apex"))
+	withDefault.SetDefaultCertificate(hostCert("default"))
+
+	tests := []struct {
+		name       string
+		hr         *HostRouter
+		serverName string
+		want       string
+	}{
+		{"exact", hr, "example.com", "apex"},
+		{"wildcard", hr, "a.example.com", "wildcard"},
+		{"wildcard not match many labels use first certificate", hr, "a.b.example.com", "apex"},
+		{"host without certificate", hr, "plain.example.org", "apex"},
+		{"no SNI", hr, "", "apex"},
+		{"default certificate", withDefault, "example.org", "default"},
+		{"no certificate", NewHostRouter(), "example.com", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := tt.hr.GetCertificate(&tls.ClientHelloInfo{ServerName: tt.serverName})
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("GetCertificate() = %v, want error", cert)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(cert.OCSPStaple) != tt.want {
+				t.Errorf("certificate = %s, want %s", cert.OCSPStaple, tt.want)
+			}
+		})
+	}
+}
+
+func TestHostRouterAddTLS(t *testing.T) {
+	if err := NewHostRouter().AddTLS("example.com", echo.New(), "missing.crt", "missing.key"); err == nil {
+		t.Error("AddTLS() of missing file without error")
+	}
+}
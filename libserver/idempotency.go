@@ -0,0 +1,230 @@
+package libserver
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/helloferdie/stdgo/libidempotency"
+	"github.com/helloferdie/stdgo/libresponse"
+	"github.com/helloferdie/stdgo/libserver/claim"
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Idempotency header
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotencyReplayed = "Idempotent-Replayed"
+)
+
+// IdempotencyConfig - Idempotency middleware config
+type IdempotencyConfig struct {
+	Store       libidempotency.Store
+	TTL         time.Duration    // Replay period of saved response, default 24 hours
+	LockTimeout time.Duration    // Period of in progress key before retry allowed, default 1 minute
+	MaxBodySize int              // Response larger than max is not saved, default 1MB
+	MaxRequest  int64            // Request body larger than max is rejected with 413, default 1MB
+	ScopeFunc   RateLimitKeyFunc // Default IdempotencyByUser
+	Required    bool             // Reject unsafe request without key
+	Skipper     middleware.Skipper
+	Name        string // Key prefix to separate endpoints with shared store
+}
+
+// IdempotencySkipHeaders - Response header not replayed
+var IdempotencySkipHeaders = []string{
+	echo.HeaderContentLength,
+	"Date",
+	echo.HeaderXRequestID,
+	"Ratelimit-Limit",
+	"Ratelimit-Remaining",
+	"Ratelimit-Reset",
+	"Retry-After",
+}
+
+// IdempotencyByUser - Scope key by JWT user_id, account_id or client_uuid, fallback to client IP
+func IdempotencyByUser(c echo.Context) string {
+	if id := claim.GetJWTUserID(c); id > 0 {
+		return "user:" + strconv.FormatInt(id, 10)
+	}
+	if id := claim.GetJWTAccountID(c); id > 0 {
+		return "account:" + strconv.FormatInt(id, 10)
+	}
+	if cl := claim.GetClaims(c); cl != nil && cl.ClientUUID != "" {
+		return "client:" + cl.ClientUUID
+	}
+	return "ip:" + GetRealIP(c)
+}
+
+// Idempotency - Honour Idempotency-Key header of unsafe request, replay saved response of same key and respond 409 while first request is in progress,
+// register after auth middleware to scope key by user
+func Idempotency(cfg IdempotencyConfig) echo.MiddlewareFunc {
+	if cfg.Store == nil {
+		cfg.Store = libidempotency.NewMemoryStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = 1 << 20
+	}
+	if cfg.MaxRequest <= 0 {
+		cfg.MaxRequest = 1 << 20
+	}
+	if cfg.ScopeFunc == nil {
+		cfg.ScopeFunc = IdempotencyByUser
+	}
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+	prefix := ""
+	if cfg.Name != "" {
+		prefix = cfg.Name + ":"
+	}
+	skipHeaders := map[string]bool{}
+	for _, h := range IdempotencySkipHeaders {
+		skipHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if cfg.Skipper(c) || isSafeMethod(req.Method) {
+				return next(c)
+			}
+
+			idemKey := req.Header.Get(HeaderIdempotencyKey)
+			if idemKey == "" {
+				if cfg.Required {
+					return idempotencyError(c, 400, "general.error_idempotency_key_required")
+				}
+				return next(c)
+			}
+			if len(idemKey) > 255 {
+				return idempotencyError(c, 400, "general.error_idempotency_key_invalid")
+			}
+
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				// Whole body is fingerprinted, cap it before key is locked
+				b, err := ioutil.ReadAll(http.MaxBytesReader(c.Response(), req.Body, cfg.MaxRequest))
+				if err != nil {
+					return echo.NewHTTPError(413, err.Error()).SetInternal(err)
+				}
+				body = b
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+			key := prefix + cfg.ScopeFunc(c) + ":" + idemKey
+			fingerprint := libidempotency.Fingerprint(req.Method, req.URL.RequestURI(), body)
+
+			rec, err := cfg.Store.Lock(key, fingerprint, cfg.LockTimeout)
+			if err != nil {
+				// Fail open when store is unavailable
+				logger.MakeLogEntry(c, false).Errorf("Fail to lock idempotency key %v", err)
+				return next(c)
+			}
+			if rec != nil {
+				if rec.Fingerprint != fingerprint {
+					return idempotencyError(c, 422, "general.error_idempotency_key_reused")
+				}
+				if !rec.Completed() {
+					c.Response().Header().Set("Retry-After", "1")
+					return idempotencyError(c, 409, "general.error_idempotency_in_progress")
+				}
+				if rec.Unavailable {
+					return idempotencyError(c, 409, "general.error_idempotency_response_unavailable")
+				}
+				h := c.Response().Header()
+				for k, v := range rec.Header {
+					h[k] = v
+				}
+				h.Set(HeaderIdempotencyReplayed, "true")
+				c.Response().WriteHeader(rec.Status)
+				_, err := c.Response().Write(rec.Body)
+				return err
+			}
+
+			failed := true
+			defer func() {
+				// Release key of failed or panic request to allow retry
+				if failed {
+					if err := cfg.Store.Delete(key); err != nil {
+						logger.MakeLogEntry(c, false).Errorf("Fail to release idempotency key %v", err)
+					}
+				}
+			}()
+
+			resCapture := &bodyCapture{ResponseWriter: c.Response().Writer, max: cfg.MaxBodySize}
+			c.Response().Writer = resCapture
+			handlerErr := next(c)
+			if handlerErr != nil {
+				// Run error handler to save final status and body, error is still returned to outer middleware
+				c.Error(handlerErr)
+			}
+
+			res := c.Response()
+			if !res.Committed || res.Status >= 500 {
+				return handlerErr
+			}
+			failed = false
+
+			rec = &libidempotency.Record{
+				Key:         key,
+				Fingerprint: fingerprint,
+				Status:      res.Status,
+				Header:      http.Header{},
+				Body:        resCapture.buf.Bytes(),
+				Unavailable: resCapture.truncated,
+				ExpiresAt:   time.Now().Add(cfg.TTL),
+			}
+			for k, v := range res.Header() {
+				if !skipHeaders[k] {
+					rec.Header[k] = v
+				}
+			}
+			if rec.Unavailable {
+				rec.Header, rec.Body = nil, nil
+			}
+			err = cfg.Store.Save(rec)
+			if err != nil && !rec.Unavailable {
+				// Keep key completed without response to reject retry of succeeded request
+				logger.MakeLogEntry(c, false).Errorf("Fail to save idempotency response %v", err)
+				rec.Header, rec.Body, rec.Unavailable = nil, nil, true
+				err = cfg.Store.Save(rec)
+			}
+			if err != nil {
+				// Pending key expires after LockTimeout
+				logger.MakeLogEntry(c, false).Errorf("Fail to save idempotency key %v", err)
+			}
+			return handlerErr
+		}
+	}
+}
+
+// isSafeMethod - Whether request method has no side effect
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// idempotencyError -
+func idempotencyError(c echo.Context, code int64, e string) error {
+	res := libresponse.GetDefault()
+	res.Code = code
+	res.Message = "general.error_request"
+	if code == 422 {
+		res.Message = "general.error_validation"
+	}
+	res.Error = e
+	return Response(c, res)
+}
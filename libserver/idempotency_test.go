@@ -0,0 +1,246 @@
+package libserver
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/libidempotency"
+
+	"github.com/labstack/echo/v4"
+)
+
+// failSaveStore - Store failing save of record with body
+type failSaveStore struct {
+	*libidempotency.MemoryStore
+}
+
+// Save -
+func (s failSaveStore) Save(rec *libidempotency.Record) error {
+	if !rec.Unavailable {
+		return errors.New("save failed")
+	}
+	return s.MemoryStore.Save(rec)
+}
+
+func TestIdempotency(t *testing.T) {
+	type step struct {
+		key    string
+		query  string
+		body   string
+		status int
+		calls  int
+		replay bool
+	}
+	tests := []struct {
+		name  string
+		cfg   IdempotencyConfig
+		steps []step
+	}{
+		{
+			name: "replay saved response",
+			steps: []step{
+				{key: "a", body: `{"a":1}`, status: 201, calls: 1},
+				{key: "a", body: `{"a":1}`, status: 201, calls: 1, replay: true},
+			},
+		},
+		{
+			name: "reject reused key with different body",
+			steps: []step{
+				{key: "a", body: `{"a":1}`, status: 201, calls: 1},
+				{key: "a", body: `{"a":2}`, status: 422, calls: 1},
+			},
+		},
+		{
+			name: "without key",
+			steps: []step{
+				{body: `{"a":1}`, status: 201, calls: 1},
+				{body: `{"a":1}`, status: 201, calls: 2},
+			},
+		},
+		{
+			name: "required key",
+			cfg:  IdempotencyConfig{Required: true},
+			steps: []step{
+				{body: `{"a":1}`, status: 400, calls: 0},
+			},
+		},
+		{
+			name: "release key of failed request",
+			steps: []step{
+				{key: "a", query: "?fail=1", status: 500, calls: 1},
+				{key: "a", query: "?fail=1", status: 500, calls: 2},
+			},
+		},
+		{
+			name: "release key of panic request",
+			steps: []step{
+				{key: "a", query: "?panic=1", status: 500, calls: 1},
+				{key: "a", query: "?panic=1", status: 500, calls: 2},
+			},
+		},
+		{
+			name: "reject request body over max",
+			cfg:  IdempotencyConfig{MaxRequest: 4},
+			steps: []step{
+				{key: "a", body: `{"a":1}`, status: 413, calls: 0},
+			},
+		},
+		{
+			name: "replay handler error below 500",
+			steps: []step{
+				{key: "a", query: "?invalid=1", status: 422, calls: 1},
+				{key: "a", query: "?invalid=1", status: 422, calls: 1, replay: true},
+			},
+		},
+		{
+			name: "reject retry of response too large to save",
+			cfg:  IdempotencyConfig{MaxBodySize: 4},
+			steps: []step{
+				{key: "a", status: 201, calls: 1},
+				{key: "a", status: 409, calls: 1},
+			},
+		},
+		{
+			name: "reject retry of response failed to save",
+			cfg:  IdempotencyConfig{Store: failSaveStore{libidempotency.NewMemoryStore()}},
+			steps: []step{
+				{key: "a", status: 201, calls: 1},
+				{key: "a", status: 409, calls: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			e := echo.New()
+			e.HTTPErrorHandler = ErrorHandler
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					defer func() {
+						if r := recover(); r != nil {
+							c.Error(echo.NewHTTPError(500, "panic"))
+						}
+					}()
+					return next(c)
+				}
+			})
+			e.POST("/x", func(c echo.Context) error {
+				calls++
+				if c.QueryParam("fail") != "" {
+					return errors.New("fail")
+				}
+				if c.QueryParam("invalid") != "" {
+					return echo.NewHTTPError(422, "invalid")
+				}
+				if c.QueryParam("panic") != "" {
+					panic("panic")
+				}
+				return c.JSON(201, map[string]interface{}{"calls": calls})
+			}, Idempotency(tt.cfg))
+
+			for i, s := range tt.steps {
+				req := httptest.NewRequest("POST", "/x"+s.query, strings.NewReader(s.body))
+				if s.key != "" {
+					req.Header.Set(HeaderIdempotencyKey, s.key)
+				}
+				rec := httptest.NewRecorder()
+				e.ServeHTTP(rec, req)
+				if rec.Code != s.status {
+					t.Errorf("step %d status = %d, want %d", i, rec.Code, s.status)
+				}
+				if calls != s.calls {
+					t.Errorf("step %d calls = %d, want %d", i, calls, s.calls)
+				}
+				if replay := rec.Header().Get(HeaderIdempotencyReplayed) == "true"; replay != s.replay {
+					t.Errorf("step %d replay = %v, want %v", i, replay, s.replay)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyReturnError(t *testing.T) {
+	for _, code := range []int{422, 500} {
+		var outerErr error
+		e := echo.New()
+		e.HTTPErrorHandler = ErrorHandler
+		e.POST("/x", func(c echo.Context) error {
+			return echo.NewHTTPError(code, "fail")
+		}, func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				outerErr = next(c)
+				return outerErr
+			}
+		}, Idempotency(IdempotencyConfig{}))
+
+		req := httptest.NewRequest("POST", "/x", nil)
+		req.Header.Set(HeaderIdempotencyKey, "a")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != code {
+			t.Errorf("status = %d, want %d", rec.Code, code)
+		}
+		if he, ok := outerErr.(*echo.HTTPError); !ok || he.Code != code {
+			t.Errorf("outer middleware error = %v, want %d", outerErr, code)
+		}
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler
+	started := make(chan struct{})
+	release := make(chan struct{})
+	e.POST("/x", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(204)
+	}, Idempotency(IdempotencyConfig{}))
+
+	done := make(chan struct{})
+	go func() {
+		req := httptest.NewRequest("POST", "/x", nil)
+		req.Header.Set(HeaderIdempotencyKey, "a")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+	<-started
+
+	req := httptest.NewRequest("POST", "/x", nil)
+	req.Header.Set(HeaderIdempotencyKey, "a")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != 409 || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("status = %d retry-after = %q, want 409 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("first request not finished")
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler
+	calls := 0
+	e.POST("/x", func(c echo.Context) error {
+		calls++
+		return c.NoContent(204)
+	}, Idempotency(IdempotencyConfig{}))
+
+	for _, ip := range []string{"10.0.0.1:1000", "10.0.0.2:1000"} {
+		req := httptest.NewRequest("POST", "/x", nil)
+		req.RemoteAddr = ip
+		req.Header.Set(HeaderIdempotencyKey, "a")
+		e.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 for anonymous clients of different IP", calls)
+	}
+}
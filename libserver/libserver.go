@@ -3,19 +3,19 @@ package libserver
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/helloferdie/stdgo/libserver/claim"
 
+	"github.com/helloferdie/stdgo/liblocale"
+	"github.com/helloferdie/stdgo/librealip"
 	"github.com/helloferdie/stdgo/libresponse"
 	"github.com/helloferdie/stdgo/libslice"
 	"github.com/helloferdie/stdgo/libstring"
@@ -35,186 +35,42 @@ type Host struct {
 func Initialize(e *echo.Echo) {
 	e.HTTPErrorHandler = ErrorHandler
 	e.Use(middleware.Recover())
+	e.Use(RequestID)
+	e.Use(Metrics)
 	e.Use(logger.EchoLogger)
 
 	e.GET("/ping", Ping)
+	e.GET("/health/live", DefaultHealth.Live)
+	e.GET("/health/ready", DefaultHealth.Ready)
 }
 
 // StartHTTP - Start server in HTTP
 func StartHTTP(svr *echo.Echo) {
-	// Start server
-	go func() {
-		if err := svr.Start(":" + os.Getenv("port")); err != http.ErrServerClosed {
-			logger.MakeLogEntry(nil, false).Error(err)
-			logger.MakeLogEntry(nil, false).Error("Fail start HTTP server")
-			logger.MakeLogEntry(nil, false).Error("Shutting down the server")
-			os.Exit(1)
-		}
-	}()
-
-	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := svr.Shutdown(ctx); err != nil {
-		logger.MakeLogEntry(nil, false).Error("Fail shutting down server")
+	err := NewServer(ConfigFromEnv(false), svr, nil).Run(context.Background())
+	if err != nil {
+		logger.MakeLogEntry(nil, false).Error(err)
+		logger.MakeLogEntry(nil, false).Error("Shutting down the server")
 		os.Exit(1)
-	} else {
-		logger.MakeLogEntry(nil, false).Info("Shutdown HTTP server - done")
 	}
 }
 
 // StartHTTPS - Start server in HTTPS
 func StartHTTPS(svr *echo.Echo, svrInternal *echo.Echo) {
-	sslCertificate := os.Getenv("ssl_certificate")
-	sslKey := os.Getenv("ssl_key")
-
-	var err error
-	var cert []byte
-	if cert, err = ioutil.ReadFile(sslCertificate); err != nil {
-		logger.MakeLogEntry(nil, false).Error("Fail to read certificate file")
-		os.Exit(1)
-	}
-
-	var key []byte
-	if key, err = ioutil.ReadFile(sslKey); err != nil {
-		logger.MakeLogEntry(nil, false).Error("Fail to read key file")
-		os.Exit(1)
-	}
-
-	s := svr.TLSServer
-	s.TLSConfig = new(tls.Config)
-	s.TLSConfig.MinVersion = tls.VersionTLS12
-	s.TLSConfig.CurvePreferences = []tls.CurveID{tls.CurveP521, tls.CurveP384, tls.CurveP256}
-	s.TLSConfig.PreferServerCipherSuites = true
-	s.TLSConfig.CipherSuites = []uint16{
-		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
-		tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
-		tls.TLS_RSA_WITH_AES_256_GCM_SHA384,
-		tls.TLS_RSA_WITH_AES_256_CBC_SHA,
-	}
-	s.TLSConfig.Certificates = make([]tls.Certificate, 1)
-	if s.TLSConfig.Certificates[0], err = tls.X509KeyPair(cert, key); err != nil {
-		logger.MakeLogEntry(nil, false).Error("Fail to match certificate with key file")
-		os.Exit(1)
-	}
-	s.Addr = ":" + os.Getenv("ssl_port")
-
-	// Start redirect server
-	svrRedir := echo.New()
-	svrRedir.Pre(middleware.HTTPSRedirect())
-	go func() {
-		if err := svrRedir.Start(":" + os.Getenv("port")); err != nil {
-			logger.MakeLogEntry(nil, false).Error(err)
-			logger.MakeLogEntry(nil, false).Error("Fail start HTTPS redirect server")
-			logger.MakeLogEntry(nil, false).Error("Shutting down server")
-			os.Exit(1)
-		}
-	}()
-
-	// Start HTTPS server
-	go func() {
-		//svr
-		if err := svr.StartServer(s); err != http.ErrServerClosed {
-			logger.MakeLogEntry(nil, false).Error(err)
-			logger.MakeLogEntry(nil, false).Error("Shutting down server")
-			os.Exit(1)
-		}
-	}()
-
-	// Start internal http server
-	if svrInternal != nil {
-		go func() {
-			if err := svrInternal.Start(":" + os.Getenv("ssl_port_internal")); err != http.ErrServerClosed {
-				logger.MakeLogEntry(nil, false).Error(err)
-				logger.MakeLogEntry(nil, false).Error("Fail start internal server")
-				logger.MakeLogEntry(nil, false).Error("Shutting down server")
-				os.Exit(1)
-			}
-		}()
-	}
-
-	// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	if err := svrRedir.Shutdown(ctx); err != nil {
-		logger.MakeLogEntry(nil, false).Error("Fail shutting down HTTPS redirect server")
-		os.Exit(1)
-	} else {
-		logger.MakeLogEntry(nil, false).Info("Shutdown HTTPS redirect server - done")
-	}
-
-	if err := svrRedir.Shutdown(ctx); err != nil {
-		logger.MakeLogEntry(nil, false).Error("Fail shutting down HTTPS server")
+	err := NewServer(ConfigFromEnv(true), svr, svrInternal).Run(context.Background())
+	if err != nil {
+		logger.MakeLogEntry(nil, false).Error(err)
+		logger.MakeLogEntry(nil, false).Error("Shutting down server")
 		os.Exit(1)
-	} else {
-		logger.MakeLogEntry(nil, false).Info("Shutdown HTTPS server - done")
-	}
-
-	if svrInternal != nil {
-		if err := svrInternal.Shutdown(ctx); err != nil {
-			logger.MakeLogEntry(nil, false).Error("Fail shutting down internal server")
-			os.Exit(1)
-		} else {
-			logger.MakeLogEntry(nil, false).Info("Shutdown internal server - done")
-		}
 	}
-	/*
-		For Sub Domain
-		sslDomain := os.Getenv("ssl_domain") + ":" + os.Getenv("port")
-		hosts := map[string]*Host{}
-		hosts[sslDomain] = &Host{svr}
-
-		e1 := echo.New()
-		e1.Any("/*", func(c echo.Context) (err error) {
-			req := c.Request()
-			res := c.Response()
-			host := hosts[req.Host]
-			if len(hosts) == 1 {
-				host = hosts[sslDomain]
-			}
-
-			if host == nil {
-				res := libresponse.GetDefault()
-				res.Success = false
-				res.Code = 404
-				return Response(c, res)
-			}
-			host.Echo.ServeHTTP(res, req)
-			return
-		})
-
-		// Start SSL server
-		go func() {
-			if err := e1.StartServer(s); err != nil {
-				logger.MakeLogEntry(nil, false).Error("Shutting down the server")
-				os.Exit(1)
-			}
-		}()
-
-		// Wait for interrupt signal to gracefully shutdown the server with a timeout of 10 seconds.
-		quit := make(chan os.Signal, 1)
-		signal.Notify(quit, os.Interrupt)
-		<-quit
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		if err := e1.Shutdown(ctx); err != nil {
-			logger.MakeLogEntry(nil, false).Error("Fail start SSL server")
-			logger.MakeLogEntry(nil, false).Error("Fail shutting down the server")
-			os.Exit(1)
-		}*/
 }
 
 // Response -
 func Response(c echo.Context, res *libresponse.Default) (err error) {
 	locale := os.Getenv("locale")
 	if locale == "1" {
-		res = ResponseLocale(res, c.Request().Header.Get("Accept-Language"))
+		lang := RequestLanguage(c)
+		res = ResponseLocale(res, lang)
+		c.Response().Header().Set("Content-Language", lang)
 
 		// Remove locale variable
 		databytes, _ := json.Marshal(res)
@@ -252,7 +108,9 @@ func ResponseProxy(res *http.Response) error {
 			resJSON.Message = "general.error_gateway"
 			resJSON.Error = "general.error_response_read"
 		}
-		resJSON = ResponseLocale(resJSON, res.Request.Header.Get("Accept-Language"))
+		lang := NegotiateLanguage(res.Request.Header.Get("Accept-Language"))
+		resJSON = ResponseLocale(resJSON, lang)
+		res.Header.Set("Content-Language", lang)
 
 		databytes, _ := json.Marshal(resJSON)
 		m := map[string]interface{}{}
@@ -272,8 +130,9 @@ func ResponseProxy(res *http.Response) error {
 	return nil
 }
 
-// ResponseLocale -
+// ResponseLocale - Localize response in best catalog language of Accept-Language header or tag
 func ResponseLocale(data *libresponse.Default, lang string) *libresponse.Default {
+	lang = NegotiateLanguage(lang)
 	if data.Message != "" {
 		if data.Message[0:1] == "!" {
 			data.MessageLocale = libstring.Ucfirst(data.Message[1:])
@@ -310,84 +169,99 @@ func ResponseLocale(data *libresponse.Default, lang string) *libresponse.Default
 
 // LoadLocale -
 func LoadLocale(syntax string, lang string, params []interface{}) string {
-	dirLocale := os.Getenv("dir_locale")
 	defaultLocale := os.Getenv("default_locale")
 	if defaultLocale == "" {
 		defaultLocale = "en"
 	}
+	escapeSyntax := []string{
+		"general.error_localization_syntax_not_valid",
+		"general.error_localization_syntax_not_found",
+		"general.error_localization_file_not_found",
+	}
+	_, isEscape := libslice.Contains(syntax, escapeSyntax)
 
 	split := strings.Split(syntax, ".")
 	if len(split) < 2 {
 		return LoadLocale("general.error_localization_syntax_not_valid", lang, []interface{}{syntax})
 	}
 
-	filename := "/" + split[0] + ".json"
-	jsonFile, err := os.Open(dirLocale + "/" + lang + filename)
-	if err != nil {
-		jsonFile, err = os.Open(dirLocale + "/" + defaultLocale + filename)
-		if err != nil {
+	catalog := LocaleCatalog()
+	namespace := split[0]
+	fileLang := lang
+	if catalog == nil || !catalog.HasNamespace(fileLang, namespace) {
+		fileLang = defaultLocale
+		if catalog == nil || !catalog.HasNamespace(fileLang, namespace) {
+			if isEscape {
+				// Avoid endless lookup without general namespace
+				return syntax
+			}
 			return LoadLocale("general.error_localization_file_not_found", lang, []interface{}{syntax})
 		}
 	}
-	defer jsonFile.Close()
 
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-	var result map[string]interface{}
-	json.Unmarshal([]byte(byteValue), &result)
-
-	val, exist := result[split[1]]
+	val, exist := catalog.Lookup(fileLang, namespace, split[1])
 	if exist {
 		localeParams := []interface{}{}
-		escapeSyntax := []string{
-			"general.error_localization_syntax_not_valid",
-			"general.error_localization_syntax_not_found",
-			"general.error_localization_file_not_found",
-		}
-
+		named := map[string]interface{}{}
 		for _, v := range params {
-			t, ok := v.(string)
-			if !ok {
-				localeParams = append(localeParams, v)
-			} else {
-				if t != "" && t[0:1] == "!" {
-					localeParams = append(localeParams, t[1:])
-				} else {
-					_, inSlice := libslice.Contains(syntax, escapeSyntax)
-					if inSlice {
-						localeParams = append(localeParams, t)
-					} else {
-						localeParams = append(localeParams, LoadLocale(t, lang, nil))
-					}
+			if m, ok := v.(map[string]interface{}); ok {
+				// Named argument of message format
+				for k, mv := range m {
+					named[k] = localeParam(mv, lang, isEscape)
 				}
+				continue
 			}
+			localeParams = append(localeParams, localeParam(v, lang, isEscape))
 		}
-		return fmt.Sprintf(val.(string), localeParams...)
+
+		if liblocale.IsMessageFormat(val) {
+			for k, v := range localeParams {
+				named[strconv.Itoa(k)] = v
+			}
+			msg, err := liblocale.Format(fileLang, val, named)
+			if err != nil {
+				logger.MakeLogEntry(nil, false).Errorf("Fail to format locale %s %v", syntax, err)
+				return val
+			}
+			return msg
+		}
+		return fmt.Sprintf(val, localeParams...)
 	}
-	if lang != defaultLocale && syntax == "general.error_localization_syntax_not_found" {
-		lang = defaultLocale
+	if isEscape {
+		// Translation missing, fallback to default locale once
+		if fileLang != defaultLocale {
+			return LoadLocale(syntax, defaultLocale, params)
+		}
+		return syntax
 	}
 	return LoadLocale("general.error_localization_syntax_not_found", lang, []interface{}{syntax})
 }
 
-// RequestLog -
-func RequestLog(c echo.Context, stdout bool) error {
-	// Read the Body content
-	var bodyBytes []byte
-	if c.Request().Body != nil {
-		bodyBytes, _ = ioutil.ReadAll(c.Request().Body)
+// localeParam - Return parameter as is, literal of !value, or localized nested key
+func localeParam(v interface{}, lang string, isEscape bool) interface{} {
+	t, ok := v.(string)
+	if !ok {
+		return v
 	}
+	if t != "" && t[0:1] == "!" {
+		return t[1:]
+	}
+	if isEscape {
+		return t
+	}
+	return LoadLocale(t, lang, nil)
+}
 
-	err := ioutil.WriteFile(os.Getenv("log_dir")+"/log_response.log", bodyBytes, 0777)
-	if err == nil {
-		// Print out
-		if stdout {
-			fmt.Println(string(bodyBytes))
-		}
-
-		// Restore the io.ReadCloser to its original state
-		c.Request().Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
+// RequestLog - Log request body capped and redacted to structured logger, prefer BodyLog middleware
+func RequestLog(c echo.Context, stdout bool) error {
+	req := c.Request()
+	b, truncated := captureBody(req, 4096)
+	body := newRedactor(DefaultRedactHeaders, DefaultRedactFields).body(b, truncated, req.Header.Get(echo.HeaderContentType))
+	logger.MakeLogEntry(c, false).WithField("request_body", body).Info("request body")
+	if stdout {
+		fmt.Println(body)
 	}
-	return err
+	return nil
 }
 
 // FormatOutputDefault -
@@ -408,28 +282,23 @@ func FormatOutputDefault(c echo.Context) map[string]interface{} {
 	tmp.AcceptTimezone = c.Request().Header.Get("Accept-TZ")
 	tmp.RealIP = GetRealIP(c)
 	tmp.RequestURL = c.Request().URL.String()
-	claims := claim.GetJWTClaims(c)
-	if claims != nil {
-		tmp.Claims = claims
-		v, ok := claims["access"].(string)
-		if ok {
-			tmp.Access = v
+	tmp.RequestID = GetRequestID(c)
+	if cl := claim.GetClaims(c); cl != nil {
+		tmp.Claims = cl.Map
+		if cl.IsLogin() {
+			tmp.Access = cl.Access
 			tmp.IsLogin = true
-			tmp.UserID = int64(claims["user_id"].(float64))
+			tmp.UserID = cl.UserID
 		}
 	}
 	m["header"] = tmp
 	return m
 }
 
-// GetRealIP -
+// GetRealIP - Return bare client IP, forwarding header is only trusted from trusted_proxies
 func GetRealIP(c echo.Context) string {
 	if c != nil {
-		ip := c.Request().Header.Get("X-Real-Ip")
-		if ip == "" {
-			ip = c.Request().RemoteAddr
-		}
-		return ip
+		return librealip.RealIP(c.Request())
 	}
 	return ""
 }
@@ -0,0 +1,160 @@
+package libserver
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/helloferdie/stdgo/liblocale"
+	"github.com/helloferdie/stdgo/libstring"
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	localeMu      sync.RWMutex
+	localeCatalog *liblocale.Catalog
+	localeLoaded  bool               // dir_locale load attempted, failure is retried by watcher
+	localeStop    context.CancelFunc // Stop watcher of dir_locale catalog
+)
+
+// SetLocaleCatalog - Use catalog for LoadLocale, e.g. loaded from embedded file system, watcher of previous dir_locale catalog is stopped
+func SetLocaleCatalog(c *liblocale.Catalog) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	if localeStop != nil {
+		localeStop()
+		localeStop = nil
+	}
+	localeCatalog = c
+	localeLoaded = true
+}
+
+// LocaleCatalog - Return catalog of LoadLocale, on first use load dir_locale and watch every locale_reload_interval seconds (default 10, 0 to disable),
+// nil until dir_locale is readable
+func LocaleCatalog() *liblocale.Catalog {
+	localeMu.RLock()
+	c, loaded := localeCatalog, localeLoaded
+	localeMu.RUnlock()
+	if loaded {
+		return c
+	}
+
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	if localeLoaded {
+		return localeCatalog
+	}
+	localeLoaded = true
+
+	dir := os.Getenv("dir_locale")
+	interval := 10 * time.Second
+	if v := os.Getenv("locale_reload_interval"); v != "" {
+		n, _ := strconv.Atoi(v)
+		interval = time.Duration(n) * time.Second
+	}
+	var ctx context.Context
+	if interval > 0 {
+		ctx, localeStop = context.WithCancel(context.Background())
+	}
+
+	c, err := liblocale.NewCatalog(dir)
+	if err != nil {
+		// Logged once, watcher retry until directory is readable
+		logger.MakeLogEntry(nil, false).Errorf("Fail to load locale catalog %v", err)
+		if ctx != nil {
+			go watchLocaleDir(ctx, dir, interval)
+		}
+		return nil
+	}
+	if ctx != nil {
+		go c.Watch(ctx, interval)
+	}
+	localeCatalog = c
+	return c
+}
+
+// watchLocaleDir - Load dir every interval until loaded, then use and watch the catalog until ctx done
+func watchLocaleDir(ctx context.Context, dir string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c, err := liblocale.NewCatalog(dir)
+			if err != nil {
+				continue
+			}
+			localeMu.Lock()
+			if ctx.Err() != nil {
+				// Replaced by SetLocaleCatalog
+				localeMu.Unlock()
+				return
+			}
+			localeCatalog = c
+			localeMu.Unlock()
+			logger.MakeLogEntry(nil, false).Info("Load locale catalog")
+			c.Watch(ctx, interval)
+			return
+		}
+	}
+}
+
+// Language override of Accept-Language, empty to disable
+var (
+	LocaleQueryParam = "lang"
+	LocaleCookie     = "lang"
+)
+
+// LocaleNegotiator - Language negotiation of response, default chain is default_locale then en
+var LocaleNegotiator = &liblocale.Negotiator{}
+
+// NegotiateLanguage - Return best catalog language of Accept-Language header or tag
+func NegotiateLanguage(header string) string {
+	defaultLocale := os.Getenv("default_locale")
+	if defaultLocale == "" {
+		defaultLocale = "en"
+	}
+	catalog := LocaleCatalog()
+	if catalog == nil {
+		return defaultLocale
+	}
+
+	n := *LocaleNegotiator
+	if len(n.Default) == 0 {
+		n.Default = []string{defaultLocale, "en"}
+	}
+	if lang := n.Negotiate(header, catalog.Languages()); lang != "" {
+		return lang
+	}
+	return defaultLocale
+}
+
+// RequestLanguage - Return language of request from query param, cookie, then Accept-Language header
+func RequestLanguage(c echo.Context) string {
+	override := ""
+	if LocaleQueryParam != "" {
+		override = c.QueryParam(LocaleQueryParam)
+	}
+	if override == "" && LocaleCookie != "" {
+		if cookie, err := c.Cookie(LocaleCookie); err == nil {
+			override = cookie.Value
+		}
+	}
+	header := c.Request().Header.Get("Accept-Language")
+	if override != "" {
+		// Override take priority, header remain as next preference
+		header = override + "," + header
+	}
+	return NegotiateLanguage(header)
+}
+
+// localizeOpenAPI - Translate error example of OpenAPI document with default locale
+func localizeOpenAPI(key string, vars []interface{}) string {
+	return libstring.Ucfirst(LoadLocale(key, NegotiateLanguage(""), vars))
+}
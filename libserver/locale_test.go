@@ -0,0 +1,214 @@
+package libserver
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/liblocale"
+	"github.com/helloferdie/stdgo/libresponse"
+
+	"github.com/labstack/echo/v4"
+)
+
+// resetLocaleCatalog - Reset catalog state of test
+func resetLocaleCatalog() {
+	SetLocaleCatalog(nil)
+	localeMu.Lock()
+	localeLoaded = false
+	localeMu.Unlock()
+}
+
+// writeLocale - Write locale file of test
+func writeLocale(t *testing.T, dir string, lang string, ns string, content string) {
+	if err := os.MkdirAll(filepath.Join(dir, lang), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, lang, ns+".json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocaleCatalogBadFile(t *testing.T) {
+	defer resetLocaleCatalog()
+	resetLocaleCatalog()
+
+	dir, _ := ioutil.TempDir("", "locale")
+	defer os.RemoveAll(dir)
+	writeLocale(t, dir, "en", "general", `{"success": "ok"}`)
+	writeLocale(t, dir, "en", "audit", `{"broken"`)
+	os.Setenv("dir_locale", dir)
+	defer os.Unsetenv("dir_locale")
+
+	c := LocaleCatalog()
+	if c == nil {
+		t.Fatal("catalog not loaded beside broken file")
+	}
+	if msg, _ := c.Lookup("en", "general", "success"); msg != "ok" {
+		t.Errorf("general.success = %q, want ok", msg)
+	}
+}
+
+func TestLocaleCatalogRetryMissingDir(t *testing.T) {
+	defer resetLocaleCatalog()
+	resetLocaleCatalog()
+
+	dir, _ := ioutil.TempDir("", "locale")
+	defer os.RemoveAll(dir)
+	localeDir := filepath.Join(dir, "locale")
+	os.Setenv("dir_locale", localeDir)
+	os.Setenv("locale_reload_interval", "1")
+	defer os.Unsetenv("dir_locale")
+	defer os.Unsetenv("locale_reload_interval")
+
+	if c := LocaleCatalog(); c != nil {
+		t.Fatal("catalog of missing directory loaded")
+	}
+	writeLocale(t, localeDir, "en", "general", `{"success": "ok"}`)
+	deadline := time.Now().Add(5 * time.Second)
+	for LocaleCatalog() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("catalog not loaded after directory is created")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if msg, _ := LocaleCatalog().Lookup("en", "general", "success"); msg != "ok" {
+		t.Errorf("general.success = %q, want ok", msg)
+	}
+}
+
+func TestSetLocaleCatalogStopWatcher(t *testing.T) {
+	defer resetLocaleCatalog()
+	resetLocaleCatalog()
+
+	dir, _ := ioutil.TempDir("", "locale")
+	defer os.RemoveAll(dir)
+	writeLocale(t, dir, "en", "general", `{"success": "ok"}`)
+	os.Setenv("dir_locale", dir)
+	defer os.Unsetenv("dir_locale")
+
+	if c := LocaleCatalog(); c == nil {
+		t.Fatal("catalog not loaded")
+	}
+	localeMu.RLock()
+	watching := localeStop != nil
+	localeMu.RUnlock()
+	if !watching {
+		t.Fatal("catalog not watched")
+	}
+
+	c, err := liblocale.NewCatalog(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLocaleCatalog(c)
+	localeMu.RLock()
+	watching = localeStop != nil
+	localeMu.RUnlock()
+	if watching || LocaleCatalog() != c {
+		t.Error("previous watcher not stopped or catalog not replaced")
+	}
+}
+
+func TestLoadLocaleMessageFormat(t *testing.T) {
+	defer resetLocaleCatalog()
+	dir := t.TempDir()
+	writeLocale(t, dir, "en", "general", `{
+		"files": "{n, plural, one {# file} other {# files}}",
+		"positional": "Page {0} of {1}",
+		"verb": "Found %v {items}",
+		"json": "Body {\"id\": 1} not valid",
+		"unclosed": "{n, plural, one {# file}",
+		"literal": "Use {name} as placeholder"
+	}`)
+	c, err := liblocale.NewCatalog(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLocaleCatalog(c)
+
+	tests := []struct {
+		syntax string
+		params []interface{}
+		want   string
+	}{
+		{"general.files", []interface{}{map[string]interface{}{"n": 2}}, "2 files"},
+		{"general.positional", []interface{}{1, 3}, "Page 1 of 3"},
+		{"general.verb", []interface{}{2}, "Found 2 {items}"},
+		{"general.json", nil, `Body {"id": 1} not valid`},
+		{"general.unclosed", nil, "{n, plural, one {# file}"},
+		{"general.literal", nil, "Use {name} as placeholder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.syntax, func(t *testing.T) {
+			if got := LoadLocale(tt.syntax, "en", tt.params); got != tt.want {
+				t.Errorf("LoadLocale(%s) = %q, want %q", tt.syntax, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResponseContentLanguage(t *testing.T) {
+	defer resetLocaleCatalog()
+	dir := t.TempDir()
+	writeLocale(t, dir, "en", "general", `{"success": "Success"}`)
+	writeLocale(t, dir, "id", "general", `{"success": "Berhasil"}`)
+	c, err := liblocale.NewCatalog(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetLocaleCatalog(c)
+	os.Setenv("locale", "1")
+	defer os.Unsetenv("locale")
+
+	tests := []struct {
+		name    string
+		target  string
+		header  string
+		cookie  string
+		lang    string
+		message string
+	}{
+		{"accept language", "/", "id", "", "id", "Berhasil"},
+		{"highest q", "/", "en;q=0.2, id;q=0.8", "", "id", "Berhasil"},
+		{"region to base", "/", "en-US, id;q=0.5", "", "en", "Success"},
+		{"unavailable to default", "/", "fr", "", "en", "Success"},
+		{"q=0 excluded", "/", "id;q=0", "", "en", "Success"},
+		{"no header", "/", "", "", "en", "Success"},
+		{"query override", "/?lang=id", "en", "", "id", "Berhasil"},
+		{"cookie override", "/", "en", "id", "id", "Berhasil"},
+		{"unavailable override keep header", "/?lang=fr", "id", "", "id", "Berhasil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			res := libresponse.GetDefault()
+			res.Success = true
+			res.Code = 200
+			res.Message = "general.success"
+			if err := Response(echo.New().NewContext(req, rec), res); err != nil {
+				t.Fatal(err)
+			}
+			if got := rec.Header().Get("Content-Language"); got != tt.lang {
+				t.Errorf("Content-Language = %q, want %q", got, tt.lang)
+			}
+			body := map[string]interface{}{}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if body["message_locale"] != tt.message {
+				t.Errorf("message_locale = %v, want %q", body["message_locale"], tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package libserver
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/helloferdie/stdgo/libmetrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	metricRequests = libmetrics.NewCounter("http_requests_total", "Total HTTP requests", "method", "route", "status")
+	metricLatency  = libmetrics.NewHistogram("http_request_duration_seconds", "HTTP request latency in seconds", nil, "method", "route", "status")
+	metricInFlight = libmetrics.NewGauge("http_requests_in_flight", "HTTP requests being served")
+)
+
+// Metrics - Record request count, latency and in flight requests by route template, method and status
+func Metrics(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		metricInFlight.Inc()
+		defer metricInFlight.Dec()
+
+		start := time.Now()
+		err := next(c)
+
+		route := c.Path()
+		if route == "" || isUnmatched(c.Handler()) {
+			// Keep label cardinality bounded for unknown path
+			route = "unmatched"
+		}
+		status := strconv.Itoa(responseStatus(c, err))
+		method := c.Request().Method
+		metricRequests.Inc(method, route, status)
+		metricLatency.Observe(time.Since(start).Seconds(), method, route, status)
+		return err
+	}
+}
+
+// responseStatus - Final status of request, error not yet handled by error handler is resolved like ErrorHandler
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// isUnmatched - Check handler is echo not found or method not allowed handler
+func isUnmatched(h echo.HandlerFunc) bool {
+	if h == nil {
+		return true
+	}
+	p := reflect.ValueOf(h).Pointer()
+	return p == reflect.ValueOf(echo.NotFoundHandler).Pointer() || p == reflect.ValueOf(echo.MethodNotAllowedHandler).Pointer()
+}
+
+// InitializeInternal - initialize standard config of internal server with /metrics endpoint
+func InitializeInternal(e *echo.Echo) {
+	e.HTTPErrorHandler = ErrorHandler
+	e.GET("/metrics", echo.WrapHandler(libmetrics.Handler()))
+}
@@ -0,0 +1,66 @@
+package libserver
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/helloferdie/stdgo/libmetrics"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestMetrics(t *testing.T) {
+	e := echo.New()
+	handled := []error{}
+	e.HTTPErrorHandler = func(err error, c echo.Context) {
+		handled = append(handled, err)
+		ErrorHandler(err, c)
+	}
+	e.Use(Metrics)
+	e.GET("/ok/:id", func(c echo.Context) error { return c.NoContent(204) })
+	e.GET("/forbidden", func(c echo.Context) error { return echo.NewHTTPError(403, "forbidden") })
+	e.GET("/fail", func(c echo.Context) error { return errors.New("fail") })
+
+	tests := []struct {
+		path   string
+		status int
+		metric string
+	}{
+		{"/ok/1", 204, `http_requests_total{method="GET",route="/ok/:id",status="204"}`},
+		{"/forbidden", 403, `http_requests_total{method="GET",route="/forbidden",status="403"}`},
+		{"/fail", 500, `http_requests_total{method="GET",route="/fail",status="500"}`},
+		{"/unknown", 404, `http_requests_total{method="GET",route="unmatched",status="404"}`},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s status = %d, want %d", tt.path, rec.Code, tt.status)
+		}
+	}
+	if len(handled) != 3 {
+		t.Errorf("error handler called %d times, want 3 (error returned once to echo)", len(handled))
+	}
+
+	var b strings.Builder
+	libmetrics.DefaultRegistry.WriteText(&b)
+	for _, tt := range tests {
+		if !strings.Contains(b.String(), tt.metric) {
+			t.Errorf("missing metric %s", tt.metric)
+		}
+	}
+}
+
+func TestNewServerInternalRoutes(t *testing.T) {
+	internal := echo.New()
+	NewServer(Config{}, echo.New(), internal)
+	count := map[string]int{}
+	for _, r := range internal.Routes() {
+		count[r.Method+" "+r.Path]++
+	}
+	if count["GET /metrics"] != 1 || count["GET /openapi.json"] != 1 {
+		t.Errorf("internal routes = %v, want /metrics and /openapi.json once", count)
+	}
+}
@@ -0,0 +1,420 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/helloferdie/stdgo/libresponse"
+	"github.com/helloferdie/stdgo/libvalidator"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Operation - Annotation of route, Request and Response are sample value of struct type
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Request     interface{}
+	Response    interface{}
+	Errors      []int
+	Auth        bool
+}
+
+// Generator - Collect annotated route and generate OpenAPI 3 document
+type Generator struct {
+	Title   string
+	Version string
+	// Localize - Translate locale key of error example, raw key is used when nil
+	Localize func(key string, vars []interface{}) string
+
+	mu         sync.RWMutex
+	operations map[string]Operation
+}
+
+// ErrorExample - Message and error locale key of error response example
+type ErrorExample struct {
+	Description string
+	Message     string
+	Error       string
+	ErrorVar    []interface{}
+}
+
+// ErrorExamples - Error response example by status code, follow libserver.ErrorHandler
+var ErrorExamples = map[int]ErrorExample{
+	400: {"Bad Request", "general.error_request", "general.error_bad_request", nil},
+	401: {"Unauthorized", "general.error_request", "general.error_unauthorized", nil},
+	403: {"Forbidden", "general.error_request", "general.error_forbidden", nil},
+	404: {"Not Found", "general.error_request", "general.error_not_found", nil},
+	409: {"Conflict", "general.error_request", "general.error_conflict", nil},
+	422: {"Unprocessable Entity", "general.error_validation", "general.error_validation_required_var", []interface{}{"general.var_id"}},
+	429: {"Too Many Requests", "general.error_request", "general.error_too_many_requests", nil},
+	500: {"Internal Server Error", "general.error_internal", "general.error_internal", nil},
+	502: {"Bad Gateway", "general.error_gateway", "general.error_service_unreachable", nil},
+	503: {"Service Unavailable", "general.error_service_unavailable", "general.error_service_unavailable", nil},
+	504: {"Gateway Timeout", "general.error_gateway", "general.error_gateway_timeout", nil},
+}
+
+// Default - Default generator
+var Default = NewGenerator("API", "1.0.0")
+
+// NewGenerator - Create generator
+func NewGenerator(title string, version string) *Generator {
+	return &Generator{
+		Title:      title,
+		Version:    version,
+		operations: map[string]Operation{},
+	}
+}
+
+// Annotate - Annotate route to default generator
+func Annotate(route *echo.Route, op Operation) *echo.Route {
+	return Default.Annotate(route, op)
+}
+
+// Annotate - Annotate route with request / response type, return route for chaining
+func (g *Generator) Annotate(route *echo.Route, op Operation) *echo.Route {
+	if route == nil {
+		return route
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.operations == nil {
+		g.operations = map[string]Operation{}
+	}
+	g.operations[route.Method+" "+route.Path] = op
+	return route
+}
+
+// Handler - Serve OpenAPI document of echo routes as JSON
+func (g *Generator) Handler(e ...*echo.Echo) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, g.Generate(e...))
+	}
+}
+
+// Type of standard response
+var (
+	typeDefault            = reflect.TypeOf(libresponse.Default{})
+	typeVarValidationError = reflect.TypeOf(libvalidator.VarValidationError{})
+)
+
+var regexPathParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+// Generate - Generate OpenAPI document of annotated routes of every echo, e.g. every host of HostRouter, first route of same method and path is used
+func (g *Generator) Generate(e ...*echo.Echo) map[string]interface{} {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	reg := newRegistry()
+	g.schemaOf(typeDefault, reg)
+	g.schemaOf(typeVarValidationError, reg)
+
+	paths := map[string]map[string]interface{}{}
+	routes := []*echo.Route{}
+	seen := map[string]bool{}
+	for _, ec := range e {
+		if ec == nil {
+			continue
+		}
+		for _, r := range ec.Routes() {
+			if !seen[r.Method+" "+r.Path] {
+				seen[r.Method+" "+r.Path] = true
+				routes = append(routes, r)
+			}
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	for _, r := range routes {
+		op, exist := g.operations[r.Method+" "+r.Path]
+		if !exist {
+			continue
+		}
+		path := regexPathParam.ReplaceAllString(r.Path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(r.Method)] = g.operation(r, op, reg)
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   g.Title,
+			"version": g.Version,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": reg.schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearerAuth": map[string]interface{}{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+	}
+	return doc
+}
+
+// operation - Build OpenAPI operation object of route
+func (g *Generator) operation(r *echo.Route, op Operation, reg *registry) map[string]interface{} {
+	o := map[string]interface{}{
+		"operationId": r.Method + " " + r.Path,
+	}
+	if op.Summary != "" {
+		o["summary"] = op.Summary
+	}
+	if op.Description != "" {
+		o["description"] = op.Description
+	}
+	if len(op.Tags) > 0 {
+		o["tags"] = op.Tags
+	}
+	if op.Auth {
+		o["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	params := []map[string]interface{}{}
+	for _, m := range regexPathParam.FindAllStringSubmatch(r.Path, -1) {
+		params = append(params, map[string]interface{}{
+			"name":     m[1],
+			"in":       "path",
+			"required": true,
+			"schema":   Schema{"type": "string"},
+		})
+	}
+
+	if op.Request != nil {
+		t := reflect.TypeOf(op.Request)
+		switch r.Method {
+		case http.MethodGet, http.MethodDelete, http.MethodHead:
+			params = append(params, g.queryParams(t, reg)...)
+		default:
+			o["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					echo.MIMEApplicationJSON: map[string]interface{}{
+						"schema": g.schemaOf(t, reg),
+					},
+				},
+			}
+		}
+	}
+	if len(params) > 0 {
+		o["parameters"] = params
+	}
+
+	data := Schema{}
+	if op.Response != nil {
+		data = g.schemaOf(reflect.TypeOf(op.Response), reg)
+	}
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "OK",
+			"content": map[string]interface{}{
+				echo.MIMEApplicationJSON: map[string]interface{}{
+					"schema": envelope(data),
+				},
+			},
+		},
+	}
+
+	label := ""
+	if op.Request != nil {
+		label = requiredLabel(g.objectSchema(reflect.TypeOf(op.Request), reg))
+	}
+	// Copy to keep append off stored operation shared by concurrent Generate
+	codes := append([]int{}, op.Errors...)
+	if op.Request != nil && !containsCode(codes, 422) {
+		codes = append(codes, 422)
+	}
+	if op.Auth && !containsCode(codes, 401) {
+		codes = append(codes, 401)
+	}
+	if !containsCode(codes, 500) {
+		codes = append(codes, 500)
+	}
+	for _, code := range codes {
+		responses[strconv.Itoa(code)] = g.errorResponse(code, label)
+	}
+	o["responses"] = responses
+	return o
+}
+
+// queryParams - Query parameter of request struct field
+func (g *Generator) queryParams(t reflect.Type, reg *registry) []map[string]interface{} {
+	s := g.objectSchema(t, reg)
+	props, _ := s["properties"].(Schema)
+	required := map[string]bool{}
+	if list, ok := s["required"].([]string); ok {
+		for _, name := range list {
+			required[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := []map[string]interface{}{}
+	for _, name := range names {
+		p := map[string]interface{}{
+			"name":   name,
+			"in":     "query",
+			"schema": props[name],
+		}
+		if required[name] {
+			p["required"] = true
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// objectSchema - Schema of type with reference resolved
+func (g *Generator) objectSchema(t reflect.Type, reg *registry) Schema {
+	s := g.schemaOf(t, reg)
+	if name, isRef := refName(s); isRef {
+		s = reg.schemas[name]
+	}
+	return s
+}
+
+// requiredLabel - Locale label of first required field, e.g. general.var_page
+func requiredLabel(s Schema) string {
+	props, _ := s["properties"].(Schema)
+	list, _ := s["required"].([]string)
+	for _, name := range list {
+		if p, ok := props[name].(Schema); ok {
+			if label, ok := p["x-locale-label"].(string); ok {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
+// envelope - Success response schema of libresponse.Default with data
+func envelope(data Schema) Schema {
+	return Schema{
+		"allOf": []Schema{
+			ref(typeDefault.String()),
+			{
+				"type": "object",
+				"properties": Schema{
+					"success": Schema{"type": "boolean", "example": true},
+					"code":    Schema{"type": "integer", "example": 200},
+					"data":    data,
+				},
+			},
+		},
+	}
+}
+
+// errorResponse - Error response with localized example of libresponse.Default, validation example use label of required field
+func (g *Generator) errorResponse(code int, label string) map[string]interface{} {
+	ex, exist := ErrorExamples[code]
+	if !exist {
+		ex = ErrorExample{http.StatusText(code), "general.error_request", "general.error_general", nil}
+	}
+	errorVar := ex.ErrorVar
+	if code == http.StatusUnprocessableEntity && label != "" {
+		errorVar = []interface{}{label}
+	}
+	if errorVar == nil {
+		errorVar = []interface{}{}
+	}
+
+	example := map[string]interface{}{
+		"success":        false,
+		"code":           code,
+		"message":        ex.Message,
+		"message_locale": g.localize(ex.Message, nil),
+		"message_var":    []interface{}{},
+		"error":          ex.Error,
+		"error_locale":   g.localize(ex.Error, errorVar),
+		"error_var":      errorVar,
+		"data":           nil,
+	}
+
+	schema := ref(typeDefault.String())
+	if code == http.StatusUnprocessableEntity {
+		field := ex.Error
+		if len(errorVar) > 0 {
+			if label, ok := errorVar[0].(string); ok {
+				field = label[strings.LastIndex(label, ".var_")+5:]
+			}
+		}
+		example["data"] = map[string]interface{}{
+			field: map[string]interface{}{
+				"error":     "general.error_validation_required",
+				"error_var": []interface{}{},
+			},
+		}
+		schema = Schema{
+			"allOf": []Schema{
+				schema,
+				{
+					"type": "object",
+					"properties": Schema{
+						"data": Schema{
+							"type":                 "object",
+							"additionalProperties": ref(typeVarValidationError.String()),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"description": ex.Description,
+		"content": map[string]interface{}{
+			echo.MIMEApplicationJSON: map[string]interface{}{
+				"schema":  schema,
+				"example": example,
+			},
+		},
+	}
+}
+
+// localize - Translate locale key with generator hook
+func (g *Generator) localize(key string, vars []interface{}) string {
+	if g.Localize == nil || key == "" {
+		return key
+	}
+	return g.Localize(key, vars)
+}
+
+// refName - Return component name of reference schema
+func refName(s Schema) (string, bool) {
+	r, ok := s["$ref"].(string)
+	if !ok {
+		return "", false
+	}
+	return strings.TrimPrefix(r, "#/components/schemas/"), true
+}
+
+// containsCode -
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
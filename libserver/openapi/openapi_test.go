@@ -0,0 +1,161 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	language "github.com/helloferdie/stdgo/language/service"
+	timezone "github.com/helloferdie/stdgo/timezone/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// createRequest - POST body of test
+type createRequest struct {
+	Email string   `json:"email" loc:"user" validate:"required,email,max=100"`
+	Name  string   `json:"name" loc:"user" validate:"required,gt=2,lt=51"`
+	Age   int64    `json:"age" validate:"omitempty,gt=17"`
+	Role  string   `json:"role" validate:"oneof=admin user"`
+	Tags  []string `json:"tags" validate:"gt=0,lt=5"`
+}
+
+// get - Value of path in decoded JSON document
+func get(t *testing.T, doc interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	v := doc
+	for _, p := range path {
+		switch k := p.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				t.Fatalf("%v: not object at %q", path, k)
+			}
+			v = m[k]
+		case int:
+			l, ok := v.([]interface{})
+			if !ok || k >= len(l) {
+				t.Fatalf("%v: not array at %d", path, k)
+			}
+			v = l[k]
+		}
+	}
+	return v
+}
+
+// generate - Decoded document of generator of echos
+func generate(t *testing.T, g *Generator, e ...*echo.Echo) map[string]interface{} {
+	b, err := json.Marshal(g.Generate(e...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]interface{}{}
+	json.Unmarshal(b, &doc)
+	return doc
+}
+
+func TestGenerate(t *testing.T) {
+	noop := func(c echo.Context) error { return nil }
+	e := echo.New()
+	g := NewGenerator("Test", "1.0.0")
+	g.Localize = func(key string, vars []interface{}) string {
+		return "localized " + key
+	}
+	g.Annotate(e.GET("/languages", noop), Operation{Summary: "List language", Request: language.ListRequest{}, Response: []language.ListRequest{}})
+	g.Annotate(e.GET("/timezones", noop), Operation{Request: timezone.ListRequest{}})
+	g.Annotate(e.POST("/users/:id", noop), Operation{Request: createRequest{}, Auth: true, Errors: []int{409}})
+	e.GET("/hidden", noop)
+
+	doc := generate(t, g, e)
+	params := map[string]map[string]interface{}{}
+	for _, p := range get(t, doc, "paths", "/languages", "get", "parameters").([]interface{}) {
+		m := p.(map[string]interface{})
+		params[m["name"].(string)] = m
+	}
+	user := get(t, doc, "components", "schemas", "openapi.createRequest", "properties")
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"unannotated route hidden", get(t, doc, "paths", "/hidden"), nil},
+		{"query param required", params["page"]["required"], true},
+		{"query param optional", params["label"]["required"], nil},
+		{"query param minimum", get(t, params["page"], "schema", "minimum"), 1.0},
+		{"query param maximum", get(t, params["items_per_page"], "schema", "maximum"), 500.0},
+		{"query param numeric string", get(t, params["id"], "schema", "pattern"), `^-?[0-9]+(\.[0-9]+)?$`},
+		{"query param locale label", get(t, params["label"], "schema", "x-locale-label"), "language.var_label"},
+		{"path param", get(t, doc, "paths", "/users/{id}", "post", "parameters", 0, "name"), "id"},
+		{"body schema", get(t, doc, "paths", "/users/{id}", "post", "requestBody", "content", "application/json", "schema", "$ref"), "#/components/schemas/openapi.createRequest"},
+		{"required fields", get(t, doc, "components", "schemas", "openapi.createRequest", "required"), []interface{}{"email", "name"}},
+		{"email format", get(t, user, "email", "format"), "email"},
+		{"max length", get(t, user, "email", "maxLength"), 100.0},
+		{"gt string length", get(t, user, "name", "minLength"), 3.0},
+		{"lt string length", get(t, user, "name", "maxLength"), 50.0},
+		{"gt number", get(t, user, "age", "minimum"), 17.0},
+		{"gt number exclusive", get(t, user, "age", "exclusiveMinimum"), true},
+		{"gt array items", get(t, user, "tags", "minItems"), 1.0},
+		{"lt array items", get(t, user, "tags", "maxItems"), 4.0},
+		{"enum", get(t, user, "role", "enum"), []interface{}{"admin", "user"}},
+		{"envelope", get(t, doc, "paths", "/languages", "get", "responses", "200", "content", "application/json", "schema", "allOf", 0, "$ref"), "#/components/schemas/libresponse.Default"},
+		{"envelope data", get(t, doc, "paths", "/languages", "get", "responses", "200", "content", "application/json", "schema", "allOf", 1, "properties", "data", "items", "$ref"), "#/components/schemas/service.ListRequest"},
+		{"name de-duplicated", get(t, doc, "paths", "/timezones", "get", "responses", "422", "content", "application/json", "example", "error_var", 0), "general.var_page"},
+		{"422 example error", get(t, doc, "paths", "/users/{id}", "post", "responses", "422", "content", "application/json", "example", "error"), "general.error_validation_required_var"},
+		{"422 example label", get(t, doc, "paths", "/users/{id}", "post", "responses", "422", "content", "application/json", "example", "error_var"), []interface{}{"user.var_email"}},
+		{"422 example data", get(t, doc, "paths", "/users/{id}", "post", "responses", "422", "content", "application/json", "example", "data", "email", "error"), "general.error_validation_required"},
+		{"422 example localized", get(t, doc, "paths", "/users/{id}", "post", "responses", "422", "content", "application/json", "example", "error_locale"), "localized general.error_validation_required_var"},
+		{"declared error", get(t, doc, "paths", "/users/{id}", "post", "responses", "409", "description"), "Conflict"},
+		{"auth error", get(t, doc, "paths", "/users/{id}", "post", "responses", "401", "description"), "Unauthorized"},
+		{"auth security", get(t, doc, "paths", "/users/{id}", "post", "security", 0, "bearerAuth"), []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("got %#v, want %#v", tt.got, tt.want)
+			}
+		})
+	}
+
+	schemas := get(t, doc, "components", "schemas").(map[string]interface{})
+	for _, name := range []string{"service.ListRequest", "timezone.service.ListRequest"} {
+		if _, exist := schemas[name]; !exist {
+			t.Errorf("missing schema %s of %v", name, reflect.ValueOf(schemas).MapKeys())
+		}
+	}
+}
+
+func TestGenerateKeepErrors(t *testing.T) {
+	e := echo.New()
+	g := NewGenerator("Test", "1.0.0")
+	errs := make([]int, 1, 4)
+	errs[0] = 404
+	g.Annotate(e.POST("/x", func(c echo.Context) error { return nil }), Operation{Request: createRequest{}, Auth: true, Errors: errs})
+
+	done := make(chan bool)
+	for i := 0; i < 4; i++ {
+		go func() {
+			g.Generate(e)
+			done <- true
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	if spare := errs[:cap(errs)]; !reflect.DeepEqual(spare, []int{404, 0, 0, 0}) {
+		t.Errorf("Generate wrote into Operation.Errors backing array %v", spare)
+	}
+}
+
+func TestGenerateEchos(t *testing.T) {
+	noop := func(c echo.Context) error { return nil }
+	a, b := echo.New(), echo.New()
+	g := NewGenerator("Test", "1.0.0")
+	g.Annotate(a.GET("/a", noop), Operation{})
+	g.Annotate(b.GET("/b", noop), Operation{})
+
+	paths := get(t, generate(t, g, a, nil, b), "paths").(map[string]interface{})
+	if len(paths) != 2 || paths["/a"] == nil || paths["/b"] == nil {
+		t.Errorf("paths = %v, want /a and /b", paths)
+	}
+}
@@ -0,0 +1,279 @@
+package openapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema - OpenAPI schema object
+type Schema map[string]interface{}
+
+// Type of special schema
+var (
+	typeTime       = reflect.TypeOf(time.Time{})
+	typeNullTime   = reflect.TypeOf(sql.NullTime{})
+	typeNullString = reflect.TypeOf(sql.NullString{})
+	typeNullInt64  = reflect.TypeOf(sql.NullInt64{})
+	typeNullInt32  = reflect.TypeOf(sql.NullInt32{})
+	typeNullFloat  = reflect.TypeOf(sql.NullFloat64{})
+	typeNullBool   = reflect.TypeOf(sql.NullBool{})
+	typeRawJSON    = reflect.TypeOf(json.RawMessage{})
+)
+
+// registry - Component schema of document, named by type
+type registry struct {
+	schemas map[string]Schema
+	names   map[reflect.Type]string
+}
+
+// newRegistry -
+func newRegistry() *registry {
+	return &registry{
+		schemas: map[string]Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// name - Component name of named struct type e.g. service.ListRequest, prefixed with parent package on conflict e.g. client.service.GetRequest
+func (reg *registry) name(t reflect.Type) (string, bool) {
+	if name, exist := reg.names[t]; exist {
+		return name, true
+	}
+	name := t.String()
+	parts := strings.Split(t.PkgPath(), "/")
+	for i := len(parts) - 2; i >= 0; i-- {
+		if _, taken := reg.schemas[name]; !taken {
+			break
+		}
+		name = parts[i] + "." + name
+	}
+	reg.names[t] = name
+	return name, false
+}
+
+// ref -
+func ref(name string) Schema {
+	return Schema{"$ref": "#/components/schemas/" + name}
+}
+
+// schemaOf - Return schema of type, named struct is added to components and referenced
+func (g *Generator) schemaOf(t reflect.Type, reg *registry) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case typeTime:
+		return Schema{"type": "string", "format": "date-time"}
+	case typeNullTime:
+		return Schema{"type": "string", "format": "date-time", "nullable": true}
+	case typeNullString:
+		return Schema{"type": "string", "nullable": true}
+	case typeNullInt64, typeNullInt32:
+		return Schema{"type": "integer", "nullable": true}
+	case typeNullFloat:
+		return Schema{"type": "number", "nullable": true}
+	case typeNullBool:
+		return Schema{"type": "boolean", "nullable": true}
+	case typeRawJSON:
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return Schema{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return Schema{"type": "number", "format": "double"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+		return Schema{"type": "array", "items": g.schemaOf(t.Elem(), reg)}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.schemaOf(t.Elem(), reg)}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, reg)
+		}
+		name, exist := reg.name(t)
+		if !exist {
+			// Placeholder first to stop recursive type
+			reg.schemas[name] = Schema{}
+			reg.schemas[name] = g.structSchema(t, reg)
+		}
+		return ref(name)
+	}
+	return Schema{}
+}
+
+// structSchema - Object schema of struct fields with json, validate and loc tags
+func (g *Generator) structSchema(t reflect.Type, reg *registry) Schema {
+	props := Schema{}
+	required := []string{}
+	g.structFields(t, reg, props, &required)
+	s := Schema{"type": "object", "properties": props}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// structFields - Add field schema of struct, embedded struct is flattened
+func (g *Generator) structFields(t reflect.Type, reg *registry, props Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omit := jsonName(f)
+		if omit {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			g.structFields(ft, reg, props, required)
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		fs := g.schemaOf(f.Type, reg)
+		if _, isRef := fs["$ref"]; isRef {
+			fs = Schema{"allOf": []Schema{fs}}
+		}
+		if applyValidate(fs, f.Tag.Get("validate")) {
+			*required = append(*required, name)
+		}
+		if loc, exist := f.Tag.Lookup("loc"); exist {
+			if loc == "" {
+				loc = "general"
+			}
+			fs["x-locale-label"] = loc + ".var_" + name
+		}
+		props[name] = fs
+	}
+}
+
+// jsonName - Return json name of field and whether field is omitted
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name := strings.SplitN(tag, ",", 2)[0]
+	if name == "" {
+		name = f.Name
+	}
+	return name, false
+}
+
+// applyValidate - Add constraint of validate tag to schema, return true when required
+func applyValidate(s Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	required := false
+	typ, _ := s["type"].(string)
+	for _, rule := range strings.Split(tag, ",") {
+		kv := strings.SplitN(rule, "=", 2)
+		key, val := kv[0], ""
+		if len(kv) == 2 {
+			val = kv[1]
+		}
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s["format"] = "email"
+		case "url", "uri":
+			s["format"] = "uri"
+		case "uuid", "uuid4":
+			s["format"] = "uuid"
+		case "numeric":
+			if typ == "string" {
+				s["pattern"] = `^-?[0-9]+(\.[0-9]+)?$`
+			}
+		case "oneof":
+			enum := []interface{}{}
+			for _, v := range strings.Fields(val) {
+				enum = append(enum, enumValue(typ, v))
+			}
+			s["enum"] = enum
+		case "min", "gte":
+			setBound(s, typ, "min", val, false)
+		case "max", "lte":
+			setBound(s, typ, "max", val, false)
+		case "gt":
+			setBound(s, typ, "min", val, true)
+		case "lt":
+			setBound(s, typ, "max", val, true)
+		case "len":
+			setBound(s, typ, "min", val, false)
+			setBound(s, typ, "max", val, false)
+		}
+	}
+	return required
+}
+
+// setBound - Set minimum / maximum by schema type, length for string and item count for array
+func setBound(s Schema, typ string, bound string, val string, exclusive bool) {
+	n, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return
+	}
+	count := int64(n)
+	if exclusive {
+		// Length and item count are whole number, exclusive bound is next count inside
+		if bound == "min" {
+			count = int64(math.Floor(n)) + 1
+		} else {
+			count = int64(math.Ceil(n)) - 1
+		}
+	}
+	switch typ {
+	case "string":
+		s[bound+"Length"] = count
+	case "array":
+		s[bound+"Items"] = count
+	case "object":
+		s[bound+"Properties"] = count
+	default:
+		if bound == "min" {
+			s["minimum"] = n
+		} else {
+			s["maximum"] = n
+		}
+		if exclusive {
+			s["exclusive"+strings.Title(bound)+"imum"] = true
+		}
+	}
+}
+
+// enumValue - Typed enum value
+func enumValue(typ string, v string) interface{} {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
@@ -0,0 +1,97 @@
+package libserver
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/helloferdie/stdgo/libratelimit"
+	"github.com/helloferdie/stdgo/libserver/claim"
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// RateLimitKeyFunc - Return rate limit key of request
+type RateLimitKeyFunc func(c echo.Context) string
+
+// RateLimitConfig - Rate limit middleware config
+type RateLimitConfig struct {
+	Limiter *libratelimit.Limiter
+	KeyFunc RateLimitKeyFunc // Default RateLimitByIP
+	Skipper middleware.Skipper
+	Name    string // Key prefix to limit endpoints separately with shared store, e.g. "login"
+}
+
+// RateLimitByIP - Key by client IP
+func RateLimitByIP(c echo.Context) string {
+	return "ip:" + GetRealIP(c)
+}
+
+// RateLimitByUser - Key by JWT user_id or account_id, fallback to client IP
+func RateLimitByUser(c echo.Context) string {
+	if id := claim.GetJWTUserID(c); id > 0 {
+		return "user:" + strconv.FormatInt(id, 10)
+	}
+	if id := claim.GetJWTAccountID(c); id > 0 {
+		return "account:" + strconv.FormatInt(id, 10)
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByClient - Key by verified JWT client_uuid claim, fallback to client IP
+func RateLimitByClient(c echo.Context) string {
+	if cl := claim.GetClaims(c); cl != nil && cl.ClientUUID != "" {
+		return "client:" + cl.ClientUUID
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimit - Limit request per key, respond 429 with Retry-After and RateLimit-* headers when exceeded,
+// panic on invalid limiter like echo middleware with invalid config
+func RateLimit(cfg RateLimitConfig) echo.MiddlewareFunc {
+	if err := cfg.Limiter.Validate(); err != nil {
+		panic("libserver: " + err.Error())
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = RateLimitByIP
+	}
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+	prefix := ""
+	if cfg.Name != "" {
+		prefix = cfg.Name + ":"
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			result, err := cfg.Limiter.Allow(prefix + cfg.KeyFunc(c))
+			if err != nil {
+				// Fail open when store is unavailable
+				logger.MakeLogEntry(c, false).Errorf("Fail to check rate limit %v", err)
+				return next(c)
+			}
+
+			h := c.Response().Header()
+			h.Set("RateLimit-Limit", strconv.FormatInt(result.Limit, 10))
+			h.Set("RateLimit-Remaining", strconv.FormatInt(result.Remaining, 10))
+			h.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			if !result.Allowed {
+				h.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				return echo.NewHTTPError(429, "too many requests")
+			}
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds - Format duration as whole seconds rounded up
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
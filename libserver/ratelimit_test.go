@@ -0,0 +1,56 @@
+package libserver
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/libratelimit"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestRateLimitByClientIgnoreHeader(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = ErrorHandler
+	e.GET("/x", func(c echo.Context) error {
+		return c.NoContent(204)
+	}, RateLimit(RateLimitConfig{
+		Limiter: libratelimit.NewTokenBucket(1, time.Hour, 0, nil),
+		KeyFunc: RateLimitByClient,
+	}))
+
+	for i, want := range []int{204, 429} {
+		req := httptest.NewRequest("GET", "/x", nil)
+		req.Header.Set("X-Client-UUID", string(rune('a'+i)))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("request %d status = %d, want %d", i, rec.Code, want)
+		}
+		if want == 429 && rec.Header().Get("Retry-After") == "" {
+			t.Error("missing Retry-After")
+		}
+	}
+}
+
+func TestRateLimitInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter *libratelimit.Limiter
+	}{
+		{"nil limiter", nil},
+		{"zero period", libratelimit.NewTokenBucket(1, 0, 0, nil)},
+		{"negative window", libratelimit.NewSlidingWindow(1, -time.Second, nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RateLimit did not panic")
+				}
+			}()
+			RateLimit(RateLimitConfig{Limiter: tt.limiter})
+		})
+	}
+}
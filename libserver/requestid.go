@@ -0,0 +1,31 @@
+package libserver
+
+import (
+	"github.com/helloferdie/stdgo/librequestid"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequestID - Accept valid X-Request-ID or generate one, store it in echo and request context and echo it in response
+func RequestID(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		id := req.Header.Get(librequestid.Header)
+		if !librequestid.Valid(id) {
+			id = librequestid.Generate()
+		}
+		req.Header.Set(librequestid.Header, id)
+		c.SetRequest(req.WithContext(librequestid.NewContext(req.Context(), id)))
+		c.Set(librequestid.EchoKey, id)
+		c.Response().Header().Set(librequestid.Header, id)
+		return next(c)
+	}
+}
+
+// GetRequestID - Return request ID of request
+func GetRequestID(c echo.Context) string {
+	if id, ok := c.Get(librequestid.EchoKey).(string); ok {
+		return id
+	}
+	return librequestid.FromContext(c.Request().Context())
+}
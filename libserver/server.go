@@ -0,0 +1,257 @@
+package libserver
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/helloferdie/stdgo/libserver/openapi"
+	"github.com/helloferdie/stdgo/logger"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// Config - Server config
+type Config struct {
+	Address           string     // HTTP listen address, used as HTTPS redirect when TLS is set
+	InternalAddress   string     // Internal HTTP listen address, empty to disable
+	TLS               *TLSConfig // Serve main server in HTTPS when set
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	BodyLimit         string // e.g. "4M", empty for no limit
+	ShutdownTimeout   time.Duration
+}
+
+// TLSConfig - Server TLS config
+type TLSConfig struct {
+	Address        string // HTTPS listen address
+	CertFile       string
+	KeyFile        string
+	Profile        string        // TLSProfileModern or TLSProfileIntermediate (default)
+	ReloadInterval time.Duration // Poll certificate and key file for change, 0 to load once
+	Config         *tls.Config   // Use as is when set, e.g. with GetCertificate
+}
+
+// Server - HTTP(S) server with internal server and graceful shutdown
+type Server struct {
+	Config   Config
+	Echo     *echo.Echo
+	Handler  http.Handler // Main server handler, default Echo
+	Internal *echo.Echo
+
+	certManager *CertManager
+}
+
+// ConfigFromEnv - Build config from env port, ssl_port, ssl_port_internal, ssl_certificate, ssl_key and ssl_reload_interval (seconds, 0 to load once)
+func ConfigFromEnv(useTLS bool) Config {
+	cfg := Config{
+		Address:           ":" + os.Getenv("port"),
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+		ShutdownTimeout:   10 * time.Second,
+	}
+	if useTLS {
+		cfg.InternalAddress = ":" + os.Getenv("ssl_port_internal")
+		cfg.TLS = &TLSConfig{
+			Address:  ":" + os.Getenv("ssl_port"),
+			CertFile: os.Getenv("ssl_certificate"),
+			KeyFile:  os.Getenv("ssl_key"),
+		}
+		if n, err := strconv.Atoi(os.Getenv("ssl_reload_interval")); err == nil && n > 0 {
+			cfg.TLS.ReloadInterval = time.Duration(n) * time.Second
+		}
+	}
+	return cfg
+}
+
+// NewServer - Create server, internal server is optional and serves /metrics and /openapi.json
+func NewServer(cfg Config, e *echo.Echo, internal *echo.Echo) *Server {
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 10 * time.Second
+	}
+	if cfg.BodyLimit != "" && e != nil {
+		e.Use(middleware.BodyLimit(cfg.BodyLimit))
+	}
+	s := &Server{
+		Config:   cfg,
+		Echo:     e,
+		Internal: internal,
+	}
+	if e != nil {
+		s.Handler = e
+	}
+	if internal != nil {
+		InitializeInternal(internal)
+		if e != nil {
+			registerOpenAPI(internal, func() []*echo.Echo {
+				return []*echo.Echo{e}
+			})
+		}
+	}
+	return s
+}
+
+// registerOpenAPI - Serve /openapi.json of routes of echos on internal server, echos is read on every request
+func registerOpenAPI(internal *echo.Echo, echos func() []*echo.Echo) {
+	if openapi.Default.Localize == nil {
+		openapi.Default.Localize = localizeOpenAPI
+	}
+	internal.GET("/openapi.json", func(c echo.Context) error {
+		return openapi.Default.Handler(echos()...)(c)
+	})
+}
+
+// NewHostServer - Create server routing main listener by host, certificate picked by SNI when TLS is set
+func NewHostServer(cfg Config, hr *HostRouter, internal *echo.Echo) *Server {
+	if cfg.TLS != nil && cfg.TLS.Config == nil {
+		cfg.TLS.Config = TLSProfile(cfg.TLS.Profile)
+		cfg.TLS.Config.GetCertificate = hr.GetCertificate
+	}
+	s := NewServer(cfg, nil, internal)
+	s.Handler = hr
+	if internal != nil {
+		registerOpenAPI(internal, hr.Echos)
+	}
+	return s
+}
+
+// listener - Named HTTP server
+type listener struct {
+	name   string
+	server *http.Server
+}
+
+// Run - Start every listener and block until ctx done, SIGINT / SIGTERM or a listener fail, then shutdown every listener
+func (s *Server) Run(ctx context.Context) error {
+	listeners, err := s.listeners()
+	if err != nil {
+		return err
+	}
+
+	if s.certManager != nil {
+		watchCtx, stopWatch := context.WithCancel(ctx)
+		defer stopWatch()
+		go s.certManager.Watch(watchCtx, s.Config.TLS.ReloadInterval)
+	}
+
+	errChan := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l listener) {
+			var err error
+			if l.server.TLSConfig != nil {
+				err = l.server.ListenAndServeTLS("", "")
+			} else {
+				err = l.server.ListenAndServe()
+			}
+			if err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("%s server: %v", l.name, err)
+				return
+			}
+			errChan <- nil
+		}(l)
+		logger.MakeLogEntry(nil, false).Infof("Start %s server on %s", l.name, l.server.Addr)
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(quit)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+	case sig := <-quit:
+		logger.MakeLogEntry(nil, false).Infof("Receive signal %v, shutting down server", sig)
+	case runErr = <-errChan:
+		if runErr == nil {
+			runErr = fmt.Errorf("%s", "server stopped unexpectedly")
+		}
+		logger.MakeLogEntry(nil, false).Error(runErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.Config.ShutdownTimeout)
+	defer cancel()
+	for _, l := range listeners {
+		if err := l.server.Shutdown(shutdownCtx); err != nil {
+			logger.MakeLogEntry(nil, false).Errorf("Fail shutting down %s server %v", l.name, err)
+			if runErr == nil {
+				runErr = err
+			}
+		} else {
+			logger.MakeLogEntry(nil, false).Infof("Shutdown %s server - done", l.name)
+		}
+	}
+	return runErr
+}
+
+// listeners - Build HTTP server of every listener
+func (s *Server) listeners() ([]listener, error) {
+	list := []listener{}
+	if s.Config.TLS == nil {
+		list = append(list, listener{name: "HTTP", server: s.httpServer(s.Config.Address, s.Handler)})
+	} else {
+		tlsCfg, m, err := s.Config.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+		s.certManager = m
+		svr := s.httpServer(s.Config.TLS.Address, s.Handler)
+		svr.TLSConfig = tlsCfg
+		list = append(list, listener{name: "HTTPS", server: svr})
+
+		if s.Config.Address != "" {
+			redir := echo.New()
+			redir.HideBanner = true
+			redir.Pre(middleware.HTTPSRedirect())
+			list = append(list, listener{name: "HTTPS redirect", server: s.httpServer(s.Config.Address, redir)})
+		}
+	}
+	if s.Internal != nil && s.Config.InternalAddress != "" {
+		list = append(list, listener{name: "internal", server: s.httpServer(s.Config.InternalAddress, s.Internal)})
+	}
+	return list, nil
+}
+
+// httpServer - Create HTTP server with config timeouts
+func (s *Server) httpServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       s.Config.ReadTimeout,
+		ReadHeaderTimeout: s.Config.ReadHeaderTimeout,
+		WriteTimeout:      s.Config.WriteTimeout,
+		IdleTimeout:       s.Config.IdleTimeout,
+	}
+}
+
+// tlsConfig - Return custom TLS config or profile config with certificate and key file, with certificate manager when reload is set
+func (t *TLSConfig) tlsConfig() (*tls.Config, *CertManager, error) {
+	if t.Config != nil {
+		return t.Config, nil, nil
+	}
+
+	cfg := TLSProfile(t.Profile)
+	if t.ReloadInterval > 0 {
+		m, err := NewCertManager(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.GetCertificate = m.GetCertificate
+		return cfg, m, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to load certificate and key file: %v", err)
+	}
+	cfg.Certificates = []tls.Certificate{cert}
+	return cfg, nil, nil
+}
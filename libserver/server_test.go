@@ -0,0 +1,63 @@
+package libserver
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/libserver/openapi"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestConfigFromEnvReloadInterval(t *testing.T) {
+	tests := []struct {
+		env  string
+		want time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"invalid", 0},
+		{"300", 5 * time.Minute},
+	}
+	defer os.Unsetenv("ssl_reload_interval")
+	for _, tt := range tests {
+		os.Setenv("ssl_reload_interval", tt.env)
+		cfg := ConfigFromEnv(true)
+		if cfg.TLS == nil || cfg.TLS.ReloadInterval != tt.want {
+			t.Errorf("ssl_reload_interval %q = %+v, want %v", tt.env, cfg.TLS, tt.want)
+		}
+	}
+	if cfg := ConfigFromEnv(false); cfg.TLS != nil {
+		t.Error("TLS config without TLS")
+	}
+}
+
+func TestNewHostServerOpenAPI(t *testing.T) {
+	noop := func(c echo.Context) error { return nil }
+	api, admin := echo.New(), echo.New()
+	openapi.Default.Annotate(api.GET("/host-api", noop), openapi.Operation{})
+	openapi.Default.Annotate(admin.GET("/host-admin", noop), openapi.Operation{})
+	hr := NewHostRouter()
+	hr.Add("api.example.com", api)
+	hr.SetFallback(admin)
+
+	internal := echo.New()
+	NewHostServer(Config{}, hr, internal)
+	rec := httptest.NewRecorder()
+	internal.ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+	if rec.Code != 200 {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	doc := struct {
+		Paths map[string]interface{} `json:"paths"`
+	}{}
+	json.Unmarshal(rec.Body.Bytes(), &doc)
+	for _, p := range []string{"/host-api", "/host-admin"} {
+		if doc.Paths[p] == nil {
+			t.Errorf("missing path %s of %v", p, doc.Paths)
+		}
+	}
+}
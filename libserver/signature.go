@@ -0,0 +1,163 @@
+package libserver
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/helloferdie/stdgo/libhttp"
+	"github.com/helloferdie/stdgo/libresponse"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// NonceCache - Remember nonce for replay protection, implement to share between instances
+type NonceCache interface {
+	// Add - Store nonce for ttl, return false when nonce already exist
+	Add(nonce string, ttl time.Duration) bool
+}
+
+// SignatureConfig - Signature verification middleware config
+type SignatureConfig struct {
+	Keys       map[string]string // Secret per key ID, default libhttp.SignatureKeysFromEnv
+	ClockSkew  time.Duration     // Accepted timestamp difference, default 5 minutes
+	NonceCache NonceCache        // Default in-memory cache
+	MaxRequest int64             // Request body larger than max is rejected with 413, default 1MB
+	Skipper    middleware.Skipper
+}
+
+// VerifySignature - Verify HMAC signature of service to service request signed by libhttp.Signer,
+// URI prefixed with X-Forwarded-Prefix is accepted for request forwarded by gateway with StripPrefix
+func VerifySignature(cfg SignatureConfig) echo.MiddlewareFunc {
+	if cfg.Keys == nil {
+		cfg.Keys = libhttp.SignatureKeysFromEnv()
+	}
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = 5 * time.Minute
+	}
+	if cfg.NonceCache == nil {
+		cfg.NonceCache = NewMemoryNonceCache()
+	}
+	if cfg.MaxRequest <= 0 {
+		cfg.MaxRequest = 1 << 20
+	}
+	if cfg.Skipper == nil {
+		cfg.Skipper = middleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if cfg.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			kid := req.Header.Get(libhttp.HeaderSignatureKeyID)
+			timestamp := req.Header.Get(libhttp.HeaderSignatureTimestamp)
+			nonce := req.Header.Get(libhttp.HeaderSignatureNonce)
+			signature := req.Header.Get(libhttp.HeaderSignature)
+			if signature == "" || timestamp == "" || nonce == "" {
+				return signatureError(c, "general.error_signature_required")
+			}
+			secret, exist := cfg.Keys[kid]
+			if !exist {
+				return signatureError(c, "general.error_signature_invalid")
+			}
+
+			ts, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil || math.Abs(time.Since(time.Unix(ts, 0)).Seconds()) > cfg.ClockSkew.Seconds() {
+				return signatureError(c, "general.error_signature_expired")
+			}
+
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				// Body is read before signature is verified, cap it for unauthenticated sender
+				body, err = ioutil.ReadAll(http.MaxBytesReader(c.Response(), req.Body, cfg.MaxRequest))
+				if err != nil {
+					return echo.NewHTTPError(413, err.Error()).SetInternal(err)
+				}
+				req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+			}
+			bodyHash := libhttp.BodyHash(body)
+			valid := false
+			for _, uri := range signedURIs(req) {
+				str := libhttp.SignatureString(req.Method, uri, timestamp, nonce, bodyHash)
+				if hmac.Equal([]byte(libhttp.Signature([]byte(secret), str)), []byte(signature)) {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return signatureError(c, "general.error_signature_invalid")
+			}
+
+			// Nonce is only recorded for valid signature, kept until timestamp leave skew window
+			if !cfg.NonceCache.Add(kid+":"+nonce, 2*cfg.ClockSkew) {
+				return signatureError(c, "general.error_signature_replay")
+			}
+			return next(c)
+		}
+	}
+}
+
+// signedURIs - Candidate URI signed by sender, original URI before gateway strip prefix
+func signedURIs(req *http.Request) []string {
+	uri := req.URL.RequestURI()
+	list := []string{uri}
+	if prefix := strings.TrimSuffix(req.Header.Get(libhttp.HeaderForwardedPrefix), "/"); prefix != "" {
+		list = append(list, prefix+uri)
+	}
+	return list
+}
+
+// signatureError - Respond 401 with signature error
+func signatureError(c echo.Context, e string) error {
+	res := libresponse.GetDefault()
+	res.Code = 401
+	res.Message = "general.error_request"
+	res.Error = e
+	return Response(c, res)
+}
+
+// MemoryNonceCache - In-memory nonce cache of single instance
+type MemoryNonceCache struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastPurge time.Time
+}
+
+// NewMemoryNonceCache - Create in-memory nonce cache
+func NewMemoryNonceCache() *MemoryNonceCache {
+	return &MemoryNonceCache{
+		nonces:    map[string]time.Time{},
+		lastPurge: time.Now(),
+	}
+}
+
+// Add - Store nonce, return false when nonce already exist
+func (m *MemoryNonceCache) Add(nonce string, ttl time.Duration) bool {
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastPurge) >= time.Minute {
+		for k, expire := range m.nonces {
+			if now.After(expire) {
+				delete(m.nonces, k)
+			}
+		}
+		m.lastPurge = now
+	}
+	if expire, exist := m.nonces[nonce]; exist && now.Before(expire) {
+		return false
+	}
+	m.nonces[nonce] = now.Add(ttl)
+	return true
+}
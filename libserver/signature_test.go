@@ -0,0 +1,163 @@
+package libserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/helloferdie/stdgo/libhttp"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestVerifySignature(t *testing.T) {
+	signer := &libhttp.Signer{KeyID: "k1", Secret: []byte("secret")}
+	body := []byte(`{"a":1}`)
+
+	tests := []struct {
+		name   string
+		path   string
+		modify func(req *httptestRequest)
+		error  string
+	}{
+		{name: "valid", path: "/audit"},
+		{name: "missing signature", path: "/audit", modify: func(r *httptestRequest) { r.Header.Del(libhttp.HeaderSignature) }, error: "general.error_signature_required"},
+		{name: "unknown key", path: "/audit", modify: func(r *httptestRequest) { r.Header.Set(libhttp.HeaderSignatureKeyID, "k2") }, error: "general.error_signature_invalid"},
+		{name: "tampered body", path: "/audit", modify: func(r *httptestRequest) { r.body = []byte(`{"a":2}`) }, error: "general.error_signature_invalid"},
+		{name: "tampered path", path: "/audit", modify: func(r *httptestRequest) { r.path = "/other" }, error: "general.error_signature_invalid"},
+		{
+			name: "expired timestamp",
+			path: "/audit",
+			modify: func(r *httptestRequest) {
+				r.resign(signer, time.Now().Add(-10*time.Minute))
+			},
+			error: "general.error_signature_expired",
+		},
+		{
+			name: "timestamp within skew",
+			path: "/audit",
+			modify: func(r *httptestRequest) {
+				r.resign(signer, time.Now().Add(-4*time.Minute))
+			},
+		},
+		{
+			name: "path stripped by gateway",
+			path: "/v1/audit",
+			modify: func(r *httptestRequest) {
+				r.path = "/audit"
+				r.Header.Set(libhttp.HeaderForwardedPrefix, "/v1")
+			},
+		},
+		{
+			name: "wrong forwarded prefix",
+			path: "/v1/audit",
+			modify: func(r *httptestRequest) {
+				r.path = "/audit"
+				r.Header.Set(libhttp.HeaderForwardedPrefix, "/v2")
+			},
+			error: "general.error_signature_invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Any("/*", func(c echo.Context) error {
+				return c.NoContent(204)
+			}, VerifySignature(SignatureConfig{Keys: map[string]string{"k1": "secret"}}))
+
+			r := newHTTPTestRequest(signer, tt.path, body)
+			if tt.modify != nil {
+				tt.modify(r)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, r.build())
+			if tt.error == "" {
+				if rec.Code != 204 {
+					t.Errorf("status = %d, want 204 (%s)", rec.Code, rec.Body.String())
+				}
+				return
+			}
+			if rec.Code != 401 || !strings.Contains(rec.Body.String(), tt.error) {
+				t.Errorf("status = %d body = %s, want 401 %s", rec.Code, rec.Body.String(), tt.error)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureReplay(t *testing.T) {
+	signer := &libhttp.Signer{KeyID: "k1", Secret: []byte("secret")}
+	e := echo.New()
+	e.POST("/audit", func(c echo.Context) error {
+		return c.NoContent(204)
+	}, VerifySignature(SignatureConfig{Keys: map[string]string{"k1": "secret"}}))
+
+	r := newHTTPTestRequest(signer, "/audit", []byte("x"))
+	for i, want := range []int{204, 401} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, r.build())
+		if rec.Code != want {
+			t.Errorf("request %d status = %d, want %d", i, rec.Code, want)
+		}
+	}
+}
+
+func TestVerifySignatureMaxRequest(t *testing.T) {
+	signer := &libhttp.Signer{KeyID: "k1", Secret: []byte("secret")}
+	tests := []struct {
+		name string
+		body []byte
+		want int
+	}{
+		{"body at max", bytes.Repeat([]byte("x"), 16), 204},
+		{"body over max", bytes.Repeat([]byte("x"), 17), 413},
+		{"empty body", nil, 204},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.POST("/audit", func(c echo.Context) error {
+				return c.NoContent(204)
+			}, VerifySignature(SignatureConfig{Keys: map[string]string{"k1": "secret"}, MaxRequest: 16}))
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, newHTTPTestRequest(signer, "/audit", tt.body).build())
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
+
+// httptestRequest - Signed request of test, built after modification
+type httptestRequest struct {
+	path   string
+	body   []byte
+	Header http.Header
+}
+
+// newHTTPTestRequest -
+func newHTTPTestRequest(signer *libhttp.Signer, path string, body []byte) *httptestRequest {
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	signer.Sign(req, body)
+	return &httptestRequest{path: path, body: body, Header: req.Header}
+}
+
+// resign - Sign request again with timestamp
+func (r *httptestRequest) resign(signer *libhttp.Signer, ts time.Time) {
+	timestamp := strconv.FormatInt(ts.Unix(), 10)
+	str := libhttp.SignatureString("POST", r.path, timestamp, r.Header.Get(libhttp.HeaderSignatureNonce), libhttp.BodyHash(r.body))
+	r.Header.Set(libhttp.HeaderSignatureTimestamp, timestamp)
+	r.Header.Set(libhttp.HeaderSignature, libhttp.Signature(signer.Secret, str))
+}
+
+// build -
+func (r *httptestRequest) build() *http.Request {
+	req := httptest.NewRequest("POST", r.path, bytes.NewReader(r.body))
+	req.Header = r.Header.Clone()
+	return req
+}
@@ -1,12 +1,16 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/helloferdie/stdgo/librealip"
+	"github.com/helloferdie/stdgo/librequestid"
+
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -35,19 +39,40 @@ func loadConfig() {
 	hasLoad = true
 }
 
-// trace - Backtrace log
+// AddHook - Add hook fired on every log entry, e.g. ship log to collector or capture log in test
+func AddHook(h logrus.Hook) {
+	if !hasLoad {
+		loadConfig()
+	}
+	lg.AddHook(h)
+}
+
+// trace - Backtrace log, frames resolved with CallersFrames so inlined logger function is still counted in stack
 func trace(stack int) string {
 	pc := make([]uintptr, 10) // at least 1 entry needed
 	runtime.Callers(stack, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
+	f, _ := runtime.CallersFrames(pc).Next()
 	dir := os.Getenv("dir_root")
-	file = strings.Replace(file, dir, "", 1)
-	return fmt.Sprintf("%s:%d %s\n", file, line, f.Name())
+	file := strings.Replace(f.File, dir, "", 1)
+	return fmt.Sprintf("%s:%d %s\n", file, f.Line, f.Function)
 }
 
 // MakeLogEntry - Write log to file and/or print out
 func MakeLogEntry(c echo.Context, doTrace bool) *logrus.Entry {
+	var ctx context.Context
+	if c != nil {
+		ctx = c.Request().Context()
+	}
+	return makeLogEntry(c, ctx, doTrace, 1)
+}
+
+// MakeLogEntryContext - Write log with request ID of ctx outside echo handler
+func MakeLogEntryContext(ctx context.Context, doTrace bool) *logrus.Entry {
+	return makeLogEntry(nil, ctx, doTrace, 2)
+}
+
+// makeLogEntry - Build log entry, skip is number of logger frames above to exclude from trace
+func makeLogEntry(c echo.Context, ctx context.Context, doTrace bool, skip int) *logrus.Entry {
 	if !hasLoad {
 		loadConfig()
 	}
@@ -55,27 +80,46 @@ func MakeLogEntry(c echo.Context, doTrace bool) *logrus.Entry {
 		"at": time.Now().UTC().Format("2006-01-02 15:04:05"),
 	}
 	if doTrace {
-		f["trace3"] = trace(3)
-		f["trace4"] = trace(4)
+		f["trace3"] = trace(3 + skip)
+		f["trace4"] = trace(4 + skip)
 	}
 	if c != nil {
 		f["method"] = c.Request().Method
 		f["uri"] = c.Request().URL.String()
 		f["ip"] = c.Request().RemoteAddr
-		f["real_ip"] = c.Request().Header.Get("X-Real-Ip")
+		f["real_ip"] = librealip.RealIP(c.Request())
 		f["proxy_ip"] = c.Request().Header.Get("X-Proxy-Ip")
 	}
+	if id := librequestid.FromContext(ctx); id != "" {
+		f["request_id"] = id
+	}
 
 	return lg.WithFields(f)
 }
 
 // PrintLogEntry - Print log entry to stdout
 func PrintLogEntry(t string, s string, doTrace bool) {
-	fmt.Printf("%s | %s \n", time.Now().UTC().Format("2006-01-02 15:04:05 -0700 MST"), s)
+	printLogEntry(nil, t, s, doTrace)
+}
+
+// PrintLogEntryContext - Print log entry with request ID of ctx to stdout
+func PrintLogEntryContext(ctx context.Context, t string, s string, doTrace bool) {
+	printLogEntry(ctx, t, s, doTrace)
+}
+
+// printLogEntry -
+func printLogEntry(ctx context.Context, t string, s string, doTrace bool) {
+	at := time.Now().UTC().Format("2006-01-02 15:04:05 -0700 MST")
+	if id := librequestid.FromContext(ctx); id != "" {
+		fmt.Printf("%s | %s | %s \n", at, id, s)
+	} else {
+		fmt.Printf("%s | %s \n", at, s)
+	}
+	entry := makeLogEntry(nil, ctx, doTrace, 2)
 	if t == "info" {
-		MakeLogEntry(nil, doTrace).Info(s)
+		entry.Info(s)
 	} else {
-		MakeLogEntry(nil, doTrace).Error(s)
+		entry.Error(s)
 	}
 }
 
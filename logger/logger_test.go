@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/helloferdie/stdgo/librequestid"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+// captureLog - Discard log output and return hook of every entry
+func captureLog(t *testing.T) *test.Hook {
+	if !hasLoad {
+		loadConfig()
+	}
+	out := lg.Out
+	lg.SetOutput(ioutil.Discard)
+	hook := test.NewLocal(lg)
+	t.Cleanup(func() {
+		lg.SetOutput(out)
+		lg.ReplaceHooks(make(map[logrus.Level][]logrus.Hook))
+	})
+	return hook
+}
+
+func TestLogEntryRequestID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/languages?page=1", nil)
+	req = req.WithContext(librequestid.NewContext(req.Context(), "req-1"))
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	plain := echo.New().NewContext(httptest.NewRequest("GET", "/", nil), httptest.NewRecorder())
+
+	tests := []struct {
+		name string
+		log  func()
+		id   string
+		uri  string
+	}{
+		{"echo context", func() { MakeLogEntry(c, false).Info("x") }, "req-1", "/languages?page=1"},
+		{"echo context without request ID", func() { MakeLogEntry(plain, false).Info("x") }, "", "/"},
+		{"nil echo context", func() { MakeLogEntry(nil, false).Info("x") }, "", ""},
+		{"context", func() { MakeLogEntryContext(req.Context(), false).Info("x") }, "req-1", ""},
+		{"nil context", func() { MakeLogEntryContext(nil, false).Info("x") }, "", ""},
+		{"print context", func() { PrintLogEntryContext(req.Context(), "info", "x", false) }, "req-1", ""},
+		{"print", func() { PrintLogEntry("error", "x", false) }, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := captureLog(t)
+			tt.log()
+			entry := hook.LastEntry()
+			if entry == nil {
+				t.Fatal("no log entry")
+			}
+			id, exist := entry.Data["request_id"]
+			if (tt.id == "" && exist) || (tt.id != "" && id != tt.id) {
+				t.Errorf("request_id = %v, want %q", id, tt.id)
+			}
+			if uri, _ := entry.Data["uri"].(string); uri != tt.uri {
+				t.Errorf("uri = %q, want %q", uri, tt.uri)
+			}
+		})
+	}
+}
+
+func TestLogEntryTrace(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func()
+	}{
+		{"make log entry", func() { MakeLogEntry(nil, true).Info("x") }},
+		{"make log entry context", func() { MakeLogEntryContext(context.Background(), true).Info("x") }},
+		{"print log entry", func() { PrintLogEntry("info", "x", true) }},
+		{"print log entry context", func() { PrintLogEntryContext(context.Background(), "error", "x", true) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := captureLog(t)
+			tt.log()
+			entry := hook.LastEntry()
+			if entry == nil {
+				t.Fatal("no log entry")
+			}
+			// Trace start at caller of logger, not logger itself
+			trace, _ := entry.Data["trace3"].(string)
+			if !strings.Contains(trace, "logger_test.go") || !strings.Contains(trace, "TestLogEntryTrace") {
+				t.Errorf("trace3 = %q, want caller in logger_test.go", trace)
+			}
+		})
+	}
+}